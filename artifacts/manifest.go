@@ -0,0 +1,213 @@
+// Package artifacts ties the constraint system, keys and Solidity verifier of a setup together through a manifest so
+// that mismatched sets are refused when they are loaded rather than producing invalid proofs.
+package artifacts
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark"
+	"github.com/consensys/gnark/backend/solidity"
+	"github.com/patrickmao1/zuika/circuits"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ManifestVersion is bumped whenever the manifest format changes
+const ManifestVersion = 2
+
+// Names of the artifacts in a manifest
+const (
+	CCS = "ccs"
+	PK  = "pk"
+	VK  = "vk"
+)
+
+const (
+	HashModeContract = "contract"
+	HashModeCircuit  = "circuit"
+)
+
+var ErrMismatch = errors.New("artifact mismatch")
+
+type CircuitParams struct {
+	Circuit                 string `json:"circuit"`
+	MaxAuthorities          int    `json:"maxAuthorities"`
+	MaxCheckpointSummaryLen int    `json:"maxCheckpointSummaryLen"`
+	// Where expand_message_xmd is computed, HashModeContract or HashModeCircuit
+	HashMode string `json:"hashMode"`
+	// Signed stake must exceed QuorumNumerator/QuorumDenominator of the total stake
+	QuorumNumerator   int `json:"quorumNumerator"`
+	QuorumDenominator int `json:"quorumDenominator"`
+}
+
+// SigVerifyParams returns the manifest parameters of SigVerifyCircuit compiled with p
+func SigVerifyParams(p circuits.SigVerifyParams) CircuitParams {
+	maxLen := p.MaxCheckpointSummaryLen
+	if maxLen == 0 {
+		maxLen = circuits.DefaultMaxCheckpointSummaryLen
+	}
+	hashMode := HashModeContract
+	if p.ExpandInCircuit {
+		hashMode = HashModeCircuit
+	}
+	num, den := p.QuorumNumerator, p.QuorumDenominator
+	if num == 0 && den == 0 {
+		num, den = circuits.DefaultQuorumNumerator, circuits.DefaultQuorumDenominator
+	}
+	return CircuitParams{
+		Circuit:                 "SigVerifyCircuit",
+		MaxAuthorities:          p.MaxAuthorities,
+		MaxCheckpointSummaryLen: maxLen,
+		HashMode:                hashMode,
+		QuorumNumerator:         num,
+		QuorumDenominator:       den,
+	}
+}
+
+type Artifact struct {
+	// Relative to the directory of the manifest
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+type Manifest struct {
+	Version      int                 `json:"version"`
+	Params       CircuitParams       `json:"params"`
+	Curve        string              `json:"curve"`
+	Backend      string              `json:"backend"`
+	GnarkVersion string              `json:"gnarkVersion"`
+	Constraints  int                 `json:"constraints"`
+	Artifacts    map[string]Artifact `json:"artifacts"`
+	// SHA-256 of the Solidity verifier exported from the vk
+	SolidityFingerprint string `json:"solidityFingerprint"`
+}
+
+// NewManifest returns the manifest of a freshly compiled circuit, artifacts are added as they are produced
+func NewManifest(params CircuitParams, nbConstraints int) *Manifest {
+	return &Manifest{
+		Version:      ManifestVersion,
+		Params:       params,
+		Curve:        "bn254",
+		Backend:      "groth16",
+		GnarkVersion: gnark.Version.String(),
+		Constraints:  nbConstraints,
+		Artifacts:    map[string]Artifact{},
+	}
+}
+
+// Add records the artifact at path under name for a manifest located at manifestPath
+func (m *Manifest) Add(manifestPath, name, path string) error {
+	digest, err := fileSHA256(path)
+	if err != nil {
+		return err
+	}
+	dir, err := filepath.Abs(filepath.Dir(manifestPath))
+	if err != nil {
+		return err
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(dir, abs)
+	if err != nil {
+		return err
+	}
+	m.Artifacts[name] = Artifact{Path: filepath.ToSlash(rel), SHA256: digest}
+	return nil
+}
+
+func (m *Manifest) WriteFile(path string) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0644)
+}
+
+func ReadManifest(path string) (*Manifest, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := new(Manifest)
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// Check refuses manifests written by another format version, gnark version or for other circuit parameters
+func (m *Manifest) Check(params CircuitParams) error {
+	if m.Version != ManifestVersion {
+		return fmt.Errorf("%w: manifest version %d, expected %d", ErrMismatch, m.Version, ManifestVersion)
+	}
+	if v := gnark.Version.String(); m.GnarkVersion != v {
+		return fmt.Errorf("%w: artifacts built with gnark %s, running gnark %s", ErrMismatch, m.GnarkVersion, v)
+	}
+	if m.Params != params {
+		return fmt.Errorf("%w: artifacts built for %+v, expected %+v", ErrMismatch, m.Params, params)
+	}
+	return nil
+}
+
+// CheckFile checks that the file at path is the named artifact of the manifest
+func (m *Manifest) CheckFile(name, path string) error {
+	a, ok := m.Artifacts[name]
+	if !ok {
+		return fmt.Errorf("%w: no %s in manifest", ErrMismatch, name)
+	}
+	digest, err := fileSHA256(path)
+	if err != nil {
+		return err
+	}
+	if digest != a.SHA256 {
+		return fmt.Errorf("%w: %s is not the %s of the manifest (sha256 %s, expected %s)",
+			ErrMismatch, path, name, digest, a.SHA256)
+	}
+	return nil
+}
+
+// CheckSolidity checks that the exported Solidity verifier is the one of the manifest's vk
+func (m *Manifest) CheckSolidity(source []byte) error {
+	if fp := sha256Hex(source); fp != m.SolidityFingerprint {
+		return fmt.Errorf("%w: Solidity verifier fingerprint %s, manifest has %s", ErrMismatch, fp, m.SolidityFingerprint)
+	}
+	return nil
+}
+
+type solidityExporter interface {
+	ExportSolidity(w io.Writer, exportOpts ...solidity.ExportOption) error
+}
+
+// SolidityFingerprint exports the Solidity verifier of vk and returns its SHA-256 along with the source
+func SolidityFingerprint(vk solidityExporter) (string, []byte, error) {
+	var buf bytes.Buffer
+	if err := vk.ExportSolidity(&buf); err != nil {
+		return "", nil, err
+	}
+	return sha256Hex(buf.Bytes()), buf.Bytes(), nil
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func sha256Hex(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,40 @@
+package artifacts
+
+import (
+	"github.com/patrickmao1/zuika/circuits"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	dir := t.TempDir()
+	ccsPath := filepath.Join(dir, "sig.ccs")
+	require.NoError(t, os.WriteFile(ccsPath, []byte("ccs"), 0644))
+	manifestPath := filepath.Join(dir, "manifest.json")
+
+	params := SigVerifyParams(circuits.SigVerifyParams{MaxAuthorities: 120})
+	m := NewManifest(params, 42)
+	require.NoError(t, m.Add(manifestPath, CCS, ccsPath))
+	require.Equal(t, "sig.ccs", m.Artifacts[CCS].Path, "path is not relative to the manifest")
+	require.NoError(t, m.WriteFile(manifestPath))
+	m, err := ReadManifest(manifestPath)
+	require.NoError(t, err)
+	require.NoError(t, m.Check(params))
+	require.NoError(t, m.CheckFile(CCS, ccsPath))
+
+	// hash mode mismatch
+	expandInCircuit := SigVerifyParams(circuits.SigVerifyParams{MaxAuthorities: 120, ExpandInCircuit: true})
+	require.ErrorIs(t, m.Check(expandInCircuit), ErrMismatch)
+	// quorum mismatch, the default quorum is explicit
+	require.NoError(t, m.Check(SigVerifyParams(circuits.SigVerifyParams{MaxAuthorities: 120, QuorumNumerator: 2, QuorumDenominator: 3})))
+	half := SigVerifyParams(circuits.SigVerifyParams{MaxAuthorities: 120, QuorumNumerator: 1, QuorumDenominator: 2})
+	require.ErrorIs(t, m.Check(half), ErrMismatch)
+	// missing artifact
+	require.ErrorIs(t, m.CheckFile(PK, ccsPath), ErrMismatch)
+	// modified artifact
+	require.NoError(t, os.WriteFile(ccsPath, []byte("other ccs"), 0644))
+	require.ErrorIs(t, m.CheckFile(CCS, ccsPath), ErrMismatch)
+	require.ErrorIs(t, m.CheckSolidity([]byte("contract Verifier {}")), ErrMismatch)
+}
@@ -0,0 +1,287 @@
+// Package ceremony runs the phase-2 MPC trusted setup of a BN254 Groth16 circuit on top of gnark's mpcsetup. The
+// ceremony lives in a transcript directory holding the phase-1 SRS, the initial phase-2 parameters and one file per
+// contribution, indexed by transcript.json.
+package ceremony
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	groth16 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/backend/groth16/bn254/mpcsetup"
+	cs "github.com/consensys/gnark/constraint/bn254"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	TranscriptFile = "transcript.json"
+	Phase1File     = "phase1.srs"
+)
+
+var ErrInvalidTranscript = errors.New("invalid transcript")
+
+type File struct {
+	// Relative to the transcript directory
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+type Contribution struct {
+	Index       int       `json:"index"`
+	Participant string    `json:"participant"`
+	File        File      `json:"file"`
+	Time        time.Time `json:"time"`
+}
+
+type Transcript struct {
+	Constraints   int            `json:"constraints"`
+	Phase1        File           `json:"phase1"`
+	Initial       File           `json:"initial"`
+	Contributions []Contribution `json:"contributions"`
+
+	dir string
+}
+
+// DomainSize returns the number of powers of tau the phase-1 SRS of a circuit with nbConstraints must have
+func DomainSize(nbConstraints int) uint64 {
+	return ecc.NextPowerOfTwo(uint64(nbConstraints))
+}
+
+// Init starts the phase-2 ceremony of r1cs in dir from the sealed phase-1 SRS commons
+func Init(dir string, r1cs *cs.R1CS, commons *mpcsetup.SrsCommons) (*Transcript, error) {
+	if err := checkCommons(r1cs, commons); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(dir, TranscriptFile)); err == nil {
+		return nil, fmt.Errorf("%s already holds a transcript", dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	t := &Transcript{Constraints: r1cs.GetNbConstraints(), Contributions: []Contribution{}, dir: dir}
+	var err error
+	if t.Phase1, err = writeFile(dir, Phase1File, commons); err != nil {
+		return nil, err
+	}
+	var p mpcsetup.Phase2
+	p.Initialize(r1cs, commons)
+	if t.Initial, err = writeFile(dir, phase2File(0), &p); err != nil {
+		return nil, err
+	}
+	return t, t.save()
+}
+
+// Open reads the transcript in dir
+func Open(dir string) (*Transcript, error) {
+	b, err := os.ReadFile(filepath.Join(dir, TranscriptFile))
+	if err != nil {
+		return nil, err
+	}
+	t := &Transcript{dir: dir}
+	if err := json.Unmarshal(b, t); err != nil {
+		return nil, fmt.Errorf("%s: %w", TranscriptFile, err)
+	}
+	return t, nil
+}
+
+// Contribute adds a contribution of participant on top of the latest one. entropy, if not empty, is mixed into the
+// randomness drawn from crypto/rand, see entropyReader. Contributions to different transcripts may run concurrently.
+func (t *Transcript) Contribute(participant string, entropy []byte) (*Contribution, error) {
+	latest := t.Initial
+	if n := len(t.Contributions); n > 0 {
+		latest = t.Contributions[n-1].File
+	}
+	var p mpcsetup.Phase2
+	if err := t.readFile(latest, &p); err != nil {
+		return nil, err
+	}
+	if err := contribute(&p, newEntropyReader(entropy)); err != nil {
+		return nil, err
+	}
+
+	index := len(t.Contributions) + 1
+	f, err := writeFile(t.dir, phase2File(index), &p)
+	if err != nil {
+		return nil, err
+	}
+	c := Contribution{Index: index, Participant: participant, File: f, Time: time.Now().UTC()}
+	t.Contributions = append(t.Contributions, c)
+	return &c, t.save()
+}
+
+type ContributionReport struct {
+	Contribution
+	// Hash of the previous contribution this one builds on
+	Challenge string `json:"challenge"`
+	Valid     bool   `json:"valid"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Report is the outcome of verifying a transcript, meant to be published along with the keys
+type Report struct {
+	Constraints   int                  `json:"constraints"`
+	Phase1        File                 `json:"phase1"`
+	Initial       File                 `json:"initial"`
+	Contributions []ContributionReport `json:"contributions"`
+	Beacon        string               `json:"beacon,omitempty"`
+	Valid         bool                 `json:"valid"`
+	Error         string               `json:"error,omitempty"`
+}
+
+// Verify recomputes the initial phase-2 parameters of r1cs and verifies every contribution against its predecessor.
+// The report lists the outcome of each contribution, the error is non-nil if any of them is invalid.
+func (t *Transcript) Verify(r1cs *cs.R1CS) (*Report, error) {
+	r, _, err := t.verify(r1cs)
+	return r, err
+}
+
+// Seal verifies the transcript and derives the proving and verifying keys from the latest contribution. beacon is a
+// public random value, e.g. a future block hash, fixed once the last contribution is in.
+func (t *Transcript) Seal(r1cs *cs.R1CS, beacon []byte) (*groth16.ProvingKey, *groth16.VerifyingKey, *Report, error) {
+	if len(beacon) == 0 {
+		return nil, nil, nil, errors.New("empty beacon")
+	}
+	r, s, err := t.verify(r1cs)
+	if err != nil {
+		return nil, nil, r, err
+	}
+	if len(t.Contributions) == 0 {
+		r.Valid, r.Error = false, "no contributions"
+		return nil, nil, r, fmt.Errorf("%w: no contributions", ErrInvalidTranscript)
+	}
+	r.Beacon = hex.EncodeToString(beacon)
+	pk, vk := s.latest.Seal(s.commons, &s.evals, beacon)
+	return &pk, &vk, r, nil
+}
+
+type verifiedState struct {
+	commons *mpcsetup.SrsCommons
+	evals   mpcsetup.Phase2Evaluations
+	latest  *mpcsetup.Phase2
+}
+
+func (t *Transcript) verify(r1cs *cs.R1CS) (*Report, *verifiedState, error) {
+	r := &Report{
+		Constraints:   t.Constraints,
+		Phase1:        t.Phase1,
+		Initial:       t.Initial,
+		Contributions: make([]ContributionReport, len(t.Contributions)),
+	}
+	for i, c := range t.Contributions {
+		r.Contributions[i].Contribution = c
+	}
+	fail := func(err error) (*Report, *verifiedState, error) {
+		r.Error = err.Error()
+		return r, nil, fmt.Errorf("%w: %w", ErrInvalidTranscript, err)
+	}
+
+	if n := r1cs.GetNbConstraints(); n != t.Constraints {
+		return fail(fmt.Errorf("transcript is for %d constraints, circuit has %d", t.Constraints, n))
+	}
+	s := &verifiedState{commons: new(mpcsetup.SrsCommons)}
+	if err := t.readFile(t.Phase1, s.commons); err != nil {
+		return fail(err)
+	}
+	if err := checkCommons(r1cs, s.commons); err != nil {
+		return fail(err)
+	}
+	// The initial parameters are recomputed rather than read so that they are bound to r1cs and the phase-1 SRS
+	prev := new(mpcsetup.Phase2)
+	s.evals = prev.Initialize(r1cs, s.commons)
+
+	valid := true
+	for i, c := range t.Contributions {
+		cr := &r.Contributions[i]
+		next := new(mpcsetup.Phase2)
+		err := t.readFile(c.File, next)
+		if err == nil {
+			err = prev.Verify(next)
+			cr.Challenge = hex.EncodeToString(next.Challenge)
+		}
+		if err != nil {
+			cr.Error = err.Error()
+			valid = false
+			// Later contributions are still checked against this one so that the report covers all of them
+		} else {
+			cr.Valid = true
+		}
+		prev = next
+	}
+	if !valid {
+		return fail(errors.New("some contributions are invalid"))
+	}
+	s.latest = prev
+	r.Valid = true
+	return r, s, nil
+}
+
+// checkCommons checks that the phase-1 SRS has exactly the powers of tau phase 2 of r1cs expects
+func checkCommons(r1cs *cs.R1CS, commons *mpcsetup.SrsCommons) error {
+	n := DomainSize(r1cs.GetNbConstraints())
+	if got := uint64(len(commons.G1.AlphaTau)); got != n {
+		return fmt.Errorf("phase-1 SRS has %d powers of tau, circuit with %d constraints needs %d",
+			got, r1cs.GetNbConstraints(), n)
+	}
+	return nil
+}
+
+func (t *Transcript) save() error {
+	b, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(t.dir, TranscriptFile), append(b, '\n'), 0644)
+}
+
+// readFile reads f into v after checking its hash against the transcript
+func (t *Transcript) readFile(f File, v io.ReaderFrom) error {
+	b, err := os.ReadFile(filepath.Join(t.dir, filepath.FromSlash(f.Path)))
+	if err != nil {
+		return err
+	}
+	if digest := sha256Hex(b); digest != f.SHA256 {
+		return fmt.Errorf("%s has sha256 %s, transcript has %s", f.Path, digest, f.SHA256)
+	}
+	if _, err := v.ReadFrom(bytes.NewReader(b)); err != nil {
+		return fmt.Errorf("reading %s: %w", f.Path, err)
+	}
+	return nil
+}
+
+func writeFile(dir, name string, v io.WriterTo) (File, error) {
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return File{}, err
+	}
+	h := sha256.New()
+	w := bufio.NewWriter(io.MultiWriter(f, h))
+	if _, err := v.WriteTo(w); err != nil {
+		f.Close()
+		return File{}, fmt.Errorf("writing %s: %w", name, err)
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return File{}, err
+	}
+	if err := f.Close(); err != nil {
+		return File{}, err
+	}
+	return File{Path: name, SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+func phase2File(index int) string {
+	return fmt.Sprintf("phase2_%04d.bin", index)
+}
+
+func sha256Hex(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,221 @@
+package ceremony
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/groth16/bn254/mpcsetup"
+	cs "github.com/consensys/gnark/constraint/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+	mrand "math/rand/v2"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+type cubeCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *cubeCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X, c.X), c.Y)
+	return nil
+}
+
+// committedCubeCircuit is cubeCircuit with a commitment, which adds a σ to phase 2
+type committedCubeCircuit struct {
+	cubeCircuit
+}
+
+func (c *committedCubeCircuit) Define(api frontend.API) error {
+	cm, err := api.(frontend.Committer).Commit(c.X)
+	if err != nil {
+		return err
+	}
+	api.AssertIsDifferent(cm, 0)
+	return c.cubeCircuit.Define(api)
+}
+
+func compile(t *testing.T, circuit frontend.Circuit) *cs.R1CS {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+	require.NoError(t, err)
+	return ccs.(*cs.R1CS)
+}
+
+func setupCeremony(t *testing.T) (string, *cs.R1CS) {
+	return setupCeremonyFor(t, &cubeCircuit{})
+}
+
+func setupCeremonyFor(t *testing.T, circuit frontend.Circuit) (string, *cs.R1CS) {
+	ccs := compile(t, circuit)
+
+	var p1 mpcsetup.Phase1
+	p1.Initialize(DomainSize(ccs.GetNbConstraints()))
+	p1.Contribute()
+	commons := p1.Seal([]byte("phase 1 beacon"))
+
+	dir := t.TempDir()
+	_, err := Init(dir, ccs, &commons)
+	require.NoError(t, err)
+	_, err = Init(dir, ccs, &commons)
+	require.Error(t, err)
+	return dir, ccs
+}
+
+func TestCeremony(t *testing.T) {
+	dir, ccs := setupCeremony(t)
+
+	tr, err := Open(dir)
+	require.NoError(t, err)
+	_, _, _, err = tr.Seal(ccs, []byte("beacon"))
+	require.ErrorIs(t, err, ErrInvalidTranscript)
+
+	_, err = tr.Contribute("alice", nil)
+	require.NoError(t, err)
+	tr, err = Open(dir)
+	require.NoError(t, err)
+	c, err := tr.Contribute("bob", []byte("bob's dice rolls"))
+	require.NoError(t, err)
+	require.Equal(t, 2, c.Index)
+
+	r, err := tr.Verify(ccs)
+	require.NoError(t, err)
+	require.True(t, r.Valid)
+	require.Len(t, r.Contributions, 2)
+
+	pk, vk, r, err := tr.Seal(ccs, []byte("beacon"))
+	require.NoError(t, err)
+	require.Equal(t, "626561636f6e", r.Beacon)
+
+	w, err := frontend.NewWitness(&cubeCircuit{X: 3, Y: 27}, ecc.BN254.ScalarField())
+	require.NoError(t, err)
+	proof, err := groth16.Prove(ccs, pk, w)
+	require.NoError(t, err)
+	pub, err := w.Public()
+	require.NoError(t, err)
+	require.NoError(t, groth16.Verify(proof, vk, pub))
+}
+
+func TestCeremonyTampered(t *testing.T) {
+	dir, ccs := setupCeremony(t)
+	tr, err := Open(dir)
+	require.NoError(t, err)
+	for _, name := range []string{"alice", "bob", "carol"} {
+		_, err = tr.Contribute(name, nil)
+		require.NoError(t, err)
+	}
+
+	// bob's contribution is replaced by one that skips alice's
+	initial, err := os.ReadFile(filepath.Join(dir, tr.Initial.Path))
+	require.NoError(t, err)
+	var p mpcsetup.Phase2
+	_, err = p.ReadFrom(bytes.NewReader(initial))
+	require.NoError(t, err)
+	p.Contribute()
+	tr.Contributions[1].File, err = writeFile(dir, "forged.bin", &p)
+	require.NoError(t, err)
+
+	r, err := tr.Verify(ccs)
+	require.True(t, errors.Is(err, ErrInvalidTranscript))
+	require.False(t, r.Valid)
+	require.True(t, r.Contributions[0].Valid)
+	require.False(t, r.Contributions[1].Valid)
+	require.False(t, r.Contributions[2].Valid)
+
+	// A file that does not match its recorded hash is refused
+	tr, err = Open(dir)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, tr.Contributions[2].File.Path), []byte("garbage"), 0644))
+	r, err = tr.Verify(ccs)
+	require.ErrorIs(t, err, ErrInvalidTranscript)
+	require.Contains(t, r.Contributions[2].Error, "sha256")
+}
+
+// Contributions do not touch crypto/rand.Reader, so they can run alongside each other and other users of crypto/rand.
+// Run with -race.
+func TestContributeConcurrent(t *testing.T) {
+	dirs := make([]string, 2)
+	ccss := make([]*cs.R1CS, 2)
+	dirs[0], ccss[0] = setupCeremony(t)
+	dirs[1], ccss[1] = setupCeremonyFor(t, &committedCubeCircuit{})
+	reader := rand.Reader
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(dirs)+1)
+	for i, dir := range dirs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tr, err := Open(dir)
+			if err == nil {
+				_, err = tr.Contribute("alice", []byte("alice's dice rolls"))
+			}
+			errs[i] = err
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		var b [32]byte
+		for i := 0; i < 100 && errs[len(dirs)] == nil; i++ {
+			_, errs[len(dirs)] = rand.Read(b[:])
+		}
+	}()
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	require.Equal(t, reader, rand.Reader)
+
+	for i, dir := range dirs {
+		tr, err := Open(dir)
+		require.NoError(t, err)
+		r, err := tr.Verify(ccss[i])
+		require.NoError(t, err)
+		require.True(t, r.Valid)
+	}
+}
+
+// contribute copies Phase2.Contribute to draw from another reader. Given the same random stream, both must produce the
+// same contribution, challenge and proofs included, since Phase2.Verify would accept a copy hashing the wrong transcript.
+func TestContributeMatchesGnark(t *testing.T) {
+	dir, _ := setupCeremonyFor(t, &committedCubeCircuit{})
+	tr, err := Open(dir)
+	require.NoError(t, err)
+	initial, err := os.ReadFile(filepath.Join(dir, tr.Initial.Path))
+	require.NoError(t, err)
+
+	var seed [32]byte
+	copy(seed[:], "contribute matches gnark")
+	read := func() *mpcsetup.Phase2 {
+		var p mpcsetup.Phase2
+		_, err := p.ReadFrom(bytes.NewReader(initial))
+		require.NoError(t, err)
+		return &p
+	}
+
+	expected := read()
+	reader := rand.Reader
+	rand.Reader = mrand.NewChaCha8(seed)
+	expected.Contribute()
+	rand.Reader = reader
+
+	actual := read()
+	require.NoError(t, contribute(actual, mrand.NewChaCha8(seed)))
+	require.Len(t, actual.Sigmas, 1)
+	require.Equal(t, expected.Challenge, actual.Challenge)
+
+	var want, got bytes.Buffer
+	_, err = expected.WriteTo(&want)
+	require.NoError(t, err)
+	_, err = actual.WriteTo(&got)
+	require.NoError(t, err)
+	require.Equal(t, want.Bytes(), got.Bytes())
+	require.NoError(t, read().Verify(actual))
+}
@@ -0,0 +1,123 @@
+package ceremony
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	cmpcsetup "github.com/consensys/gnark-crypto/ecc/bn254/mpcsetup"
+	"github.com/consensys/gnark/backend/groth16/bn254/mpcsetup"
+	"io"
+	"math/big"
+	"runtime"
+	"sync"
+)
+
+// newEntropyReader returns crypto/rand.Reader with the participant's entropy, if not empty, mixed in
+func newEntropyReader(entropy []byte) io.Reader {
+	if len(entropy) == 0 {
+		return rand.Reader
+	}
+	return &entropyReader{system: rand.Reader, seed: sha256.Sum256(entropy)}
+}
+
+// entropyReader XORs the system randomness with a SHA-256 counter stream seeded by the participant's entropy, so its
+// output is unpredictable as long as either of them is
+type entropyReader struct {
+	system  io.Reader
+	seed    [32]byte
+	counter uint64
+	block   []byte
+}
+
+func (r *entropyReader) Read(p []byte) (int, error) {
+	n, err := io.ReadFull(r.system, p)
+	for i := 0; i < n; i++ {
+		if len(r.block) == 0 {
+			var ctr [8]byte
+			binary.BigEndian.PutUint64(ctr[:], r.counter)
+			r.counter++
+			block := sha256.Sum256(append(r.seed[:], ctr[:]...))
+			r.block = block[:]
+		}
+		p[i] ^= r.block[0]
+		r.block = r.block[1:]
+	}
+	return n, err
+}
+
+// contribute is mpcsetup.Phase2.Contribute with δ and σᵢ drawn from rng. Phase2.Contribute can only draw them from
+// crypto/rand.Reader, which is shared by the whole process. The contribution is checked by Phase2.Verify like any
+// other, and TestContributeMatchesGnark checks that both produce the same parameters from the same random stream.
+func contribute(p *mpcsetup.Phase2, rng io.Reader) error {
+	h := sha256.New()
+	if _, err := p.WriteTo(h); err != nil {
+		return err
+	}
+	p.Challenge = h.Sum(nil)
+
+	var delta, deltaInv fr.Element
+	if err := randomScalar(rng, &delta); err != nil {
+		return err
+	}
+	p.Delta = cmpcsetup.UpdateValues(&delta, p.Challenge, mpcsetup.DST_DELTA)
+
+	sigma := make([]fr.Element, len(p.Parameters.G1.SigmaCKK))
+	if len(sigma) > 255 {
+		return errors.New("too many commitments") // DST collision
+	}
+	for i := range sigma {
+		if err := randomScalar(rng, &sigma[i]); err != nil {
+			return err
+		}
+		p.Sigmas[i] = cmpcsetup.UpdateValues(&sigma[i], p.Challenge, mpcsetup.DST_SIGMA+byte(i))
+		p.Parameters.G2.Sigma[i].ScalarMultiplication(&p.Parameters.G2.Sigma[i], sigma[i].BigInt(new(big.Int)))
+		scaleG1(p.Parameters.G1.SigmaCKK[i], &sigma[i])
+	}
+
+	p.Parameters.G2.Delta.ScalarMultiplication(&p.Parameters.G2.Delta, delta.BigInt(new(big.Int)))
+	p.Parameters.G1.Delta.ScalarMultiplication(&p.Parameters.G1.Delta, delta.BigInt(new(big.Int)))
+	deltaInv.Inverse(&delta)
+	scaleG1(p.Parameters.G1.Z, &deltaInv)
+	scaleG1(p.Parameters.G1.PKK, &deltaInv)
+	return nil
+}
+
+// randomScalar sets e to a non-zero scalar read from rng by rejection sampling. It consumes rng the way
+// fr.Element.SetRandom consumes crypto/rand.Reader, so that contribute draws the same δ and σᵢ as Phase2.Contribute.
+func randomScalar(rng io.Reader, e *fr.Element) error {
+	var b [fr.Bytes]byte
+	for e.IsZero() {
+		if _, err := io.ReadFull(rng, b[:]); err != nil {
+			return err
+		}
+		// Candidates are the Montgomery limbs, little-endian, with the bits above the modulus' cleared
+		b[fr.Bytes-1] &= 1<<(fr.Bits%8) - 1
+		if new(big.Int).SetBytes(reversed(b[:])).Cmp(fr.Modulus()) >= 0 {
+			continue
+		}
+		for i := range e {
+			e[i] = binary.LittleEndian.Uint64(b[8*i:])
+		}
+	}
+	return nil
+}
+
+func scaleG1(points []curve.G1Affine, s *fr.Element) {
+	scalar := s.BigInt(new(big.Int))
+	nbWorkers := runtime.NumCPU()
+	chunk := (len(points) + nbWorkers - 1) / nbWorkers
+	var wg sync.WaitGroup
+	for start := 0; start < len(points); start += chunk {
+		wg.Add(1)
+		go func(part []curve.G1Affine) {
+			defer wg.Done()
+			for i := range part {
+				part[i].ScalarMultiplication(&part[i], scalar)
+			}
+		}(points[start:min(start+chunk, len(points))])
+	}
+	wg.Wait()
+}
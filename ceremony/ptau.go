@@ -0,0 +1,324 @@
+package ceremony
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/groth16/bn254/mpcsetup"
+	"io"
+)
+
+// Sections of a snarkjs Powers-of-Tau file
+const (
+	ptauHeader  = 1
+	ptauTauG1   = 2
+	ptauTauG2   = 3
+	ptauAlphaG1 = 4
+	ptauBetaG1  = 5
+	ptauBetaG2  = 6
+)
+
+var ErrNotEnoughPowers = errors.New("not enough powers of tau")
+
+// PtauHeader is the header section of a .ptau file
+type PtauHeader struct {
+	// The file holds 2^Power powers of tau
+	Power         uint32
+	CeremonyPower uint32
+}
+
+type ptauSection struct {
+	offset int64
+	size   int64
+}
+
+// ReadPtau converts the BN254 Powers-of-Tau transcript r, in the snarkjs .ptau format, into the phase-1 SRS commons of
+// a circuit with nbConstraints constraints. The powers are truncated to the circuit's domain and checked to be
+// consistent powers of the same tau.
+func ReadPtau(r io.ReadSeeker, nbConstraints int) (*mpcsetup.SrsCommons, *PtauHeader, error) {
+	sections, err := readPtauSections(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	header, err := readPtauHeader(r, sections)
+	if err != nil {
+		return nil, nil, err
+	}
+	n := DomainSize(nbConstraints)
+	if have := uint64(1) << header.Power; have < n {
+		return nil, nil, fmt.Errorf("%w: ptau has 2^%d powers, circuit with %d constraints needs %d",
+			ErrNotEnoughPowers, header.Power, nbConstraints, n)
+	}
+
+	commons := new(mpcsetup.SrsCommons)
+	if commons.G1.Tau, err = readPtauG1(r, sections, ptauTauG1, 2*n-1); err != nil {
+		return nil, nil, err
+	}
+	if commons.G1.AlphaTau, err = readPtauG1(r, sections, ptauAlphaG1, n); err != nil {
+		return nil, nil, err
+	}
+	if commons.G1.BetaTau, err = readPtauG1(r, sections, ptauBetaG1, n); err != nil {
+		return nil, nil, err
+	}
+	if commons.G2.Tau, err = readPtauG2(r, sections, ptauTauG2, n); err != nil {
+		return nil, nil, err
+	}
+	beta, err := readPtauG2(r, sections, ptauBetaG2, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	commons.G2.Beta = beta[0]
+	if err := checkCommonsPowers(commons); err != nil {
+		return nil, nil, err
+	}
+	return commons, header, nil
+}
+
+func readPtauSections(r io.ReadSeeker) (map[uint32]ptauSection, error) {
+	var head struct {
+		Magic     [4]byte
+		Version   uint32
+		NSections uint32
+	}
+	if err := binary.Read(r, binary.LittleEndian, &head); err != nil {
+		return nil, fmt.Errorf("ptau header: %w", err)
+	}
+	if string(head.Magic[:]) != "ptau" {
+		return nil, errors.New("not a ptau file")
+	}
+	offset := int64(12)
+	sections := map[uint32]ptauSection{}
+	for i := uint32(0); i < head.NSections; i++ {
+		var sh struct {
+			Type uint32
+			Size uint64
+		}
+		if err := binary.Read(r, binary.LittleEndian, &sh); err != nil {
+			return nil, fmt.Errorf("ptau section %d: %w", i, err)
+		}
+		offset += 12
+		if _, ok := sections[sh.Type]; ok {
+			return nil, fmt.Errorf("duplicate ptau section %d", sh.Type)
+		}
+		sections[sh.Type] = ptauSection{offset: offset, size: int64(sh.Size)}
+		offset += int64(sh.Size)
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	return sections, nil
+}
+
+func readPtauHeader(r io.ReadSeeker, sections map[uint32]ptauSection) (*PtauHeader, error) {
+	if err := seekPtauSection(r, sections, ptauHeader, 4+fp.Bytes+8); err != nil {
+		return nil, err
+	}
+	var n8 uint32
+	if err := binary.Read(r, binary.LittleEndian, &n8); err != nil {
+		return nil, err
+	}
+	if n8 != fp.Bytes {
+		return nil, fmt.Errorf("ptau field elements are %d bytes, expected %d", n8, fp.Bytes)
+	}
+	q := make([]byte, fp.Bytes)
+	if _, err := io.ReadFull(r, q); err != nil {
+		return nil, err
+	}
+	expected := make([]byte, fp.Bytes)
+	fp.Modulus().FillBytes(expected)
+	if !bytes.Equal(reversed(q), expected) {
+		return nil, errors.New("ptau is not over BN254")
+	}
+	h := new(PtauHeader)
+	if err := binary.Read(r, binary.LittleEndian, h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func readPtauG1(r io.ReadSeeker, sections map[uint32]ptauSection, section uint32, n uint64) ([]curve.G1Affine, error) {
+	if err := seekPtauSection(r, sections, section, int64(n)*2*fp.Bytes); err != nil {
+		return nil, err
+	}
+	points := make([]curve.G1Affine, n)
+	buf := make([]byte, 2*fp.Bytes)
+	for i := range points {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		if err := setPtauCoordinates(buf, &points[i].X, &points[i].Y); err != nil {
+			return nil, fmt.Errorf("ptau section %d point %d: %w", section, i, err)
+		}
+		if !points[i].IsOnCurve() {
+			return nil, fmt.Errorf("ptau section %d point %d is not on the curve", section, i)
+		}
+	}
+	return points, nil
+}
+
+func readPtauG2(r io.ReadSeeker, sections map[uint32]ptauSection, section uint32, n uint64) ([]curve.G2Affine, error) {
+	if err := seekPtauSection(r, sections, section, int64(n)*4*fp.Bytes); err != nil {
+		return nil, err
+	}
+	points := make([]curve.G2Affine, n)
+	buf := make([]byte, 4*fp.Bytes)
+	for i := range points {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		p := &points[i]
+		if err := setPtauCoordinates(buf, &p.X.A0, &p.X.A1, &p.Y.A0, &p.Y.A1); err != nil {
+			return nil, fmt.Errorf("ptau section %d point %d: %w", section, i, err)
+		}
+		if !p.IsOnCurve() || !p.IsInSubGroup() {
+			return nil, fmt.Errorf("ptau section %d point %d is not in G2", section, i)
+		}
+	}
+	return points, nil
+}
+
+// seekPtauSection positions r at the start of section, which must hold at least size bytes
+func seekPtauSection(r io.ReadSeeker, sections map[uint32]ptauSection, section uint32, size int64) error {
+	s, ok := sections[section]
+	if !ok {
+		return fmt.Errorf("ptau has no section %d", section)
+	}
+	if s.size < size {
+		return fmt.Errorf("ptau section %d has %d bytes, expected at least %d", section, s.size, size)
+	}
+	_, err := r.Seek(s.offset, io.SeekStart)
+	return err
+}
+
+var fpModulusLimbs = func() [fp.Limbs]uint64 {
+	var limbs [fp.Limbs]uint64
+	b := make([]byte, fp.Bytes)
+	fp.Modulus().FillBytes(b)
+	for i := range limbs {
+		limbs[i] = binary.BigEndian.Uint64(b[fp.Bytes-8*(i+1):])
+	}
+	return limbs
+}()
+
+// setPtauCoordinates sets the coordinates from b, which holds them as little-endian Montgomery forms like
+// fp.Element does in memory
+func setPtauCoordinates(b []byte, coordinates ...*fp.Element) error {
+	for i, e := range coordinates {
+		for j := range e {
+			e[j] = binary.LittleEndian.Uint64(b[i*fp.Bytes+8*j:])
+		}
+		for j := fp.Limbs - 1; j >= 0; j-- {
+			if e[j] < fpModulusLimbs[j] {
+				break
+			}
+			if e[j] > fpModulusLimbs[j] || j == 0 {
+				return errors.New("coordinate is not reduced")
+			}
+		}
+	}
+	return nil
+}
+
+// checkCommonsPowers checks with random linear combinations that every sequence of the commons holds successive
+// powers of the same tau, and that beta agrees in G1 and G2
+func checkCommonsPowers(c *mpcsetup.SrsCommons) error {
+	_, _, g1, g2 := curve.Generators()
+	if !c.G1.Tau[0].Equal(&g1) || !c.G2.Tau[0].Equal(&g2) {
+		return errors.New("ptau powers do not start from the generators")
+	}
+	tauG1, tauG2 := g1, g2
+	if len(c.G1.Tau) >= 2 && len(c.G2.Tau) >= 2 {
+		tauG1, tauG2 = c.G1.Tau[1], c.G2.Tau[1]
+	}
+	for _, s := range []struct {
+		name   string
+		points []curve.G1Affine
+	}{{"tau", c.G1.Tau}, {"alpha tau", c.G1.AlphaTau}, {"beta tau", c.G1.BetaTau}} {
+		if len(s.points) < 2 {
+			continue
+		}
+		lo, hi, err := g1Powers(s.points)
+		if err != nil {
+			return err
+		}
+		// e(Σ rᵢ·xτⁱ, τ) = e(Σ rᵢ·xτⁱ⁺¹, 1)
+		ok, err := curve.PairingCheck([]curve.G1Affine{lo, *new(curve.G1Affine).Neg(&hi)}, []curve.G2Affine{tauG2, g2})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("ptau %s G1 powers are inconsistent", s.name)
+		}
+	}
+	if len(c.G2.Tau) >= 2 {
+		lo, hi, err := g2Powers(c.G2.Tau)
+		if err != nil {
+			return err
+		}
+		ok, err := curve.PairingCheck([]curve.G1Affine{tauG1, *new(curve.G1Affine).Neg(&g1)}, []curve.G2Affine{lo, hi})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("ptau tau G2 powers are inconsistent")
+		}
+	}
+	// e(β, 1) = e(1, β)
+	ok, err := curve.PairingCheck([]curve.G1Affine{c.G1.BetaTau[0], *new(curve.G1Affine).Neg(&g1)},
+		[]curve.G2Affine{g2, c.G2.Beta})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("ptau beta G1 and G2 are inconsistent")
+	}
+	return nil
+}
+
+// g1Powers returns Σ rᵢ·points[i] and Σ rᵢ·points[i+1] for random rᵢ
+func g1Powers(points []curve.G1Affine) (lo, hi curve.G1Affine, err error) {
+	r, err := randomScalars(len(points) - 1)
+	if err != nil {
+		return lo, hi, err
+	}
+	if _, err := lo.MultiExp(points[:len(points)-1], r, ecc.MultiExpConfig{}); err != nil {
+		return lo, hi, err
+	}
+	_, err = hi.MultiExp(points[1:], r, ecc.MultiExpConfig{})
+	return lo, hi, err
+}
+
+func g2Powers(points []curve.G2Affine) (lo, hi curve.G2Affine, err error) {
+	r, err := randomScalars(len(points) - 1)
+	if err != nil {
+		return lo, hi, err
+	}
+	if _, err := lo.MultiExp(points[:len(points)-1], r, ecc.MultiExpConfig{}); err != nil {
+		return lo, hi, err
+	}
+	_, err = hi.MultiExp(points[1:], r, ecc.MultiExpConfig{})
+	return lo, hi, err
+}
+
+func randomScalars(n int) ([]fr.Element, error) {
+	r := make([]fr.Element, n)
+	for i := range r {
+		if _, err := r[i].SetRandom(); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func reversed(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+	return r
+}
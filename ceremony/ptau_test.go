@@ -0,0 +1,147 @@
+package ceremony
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+// ptauFile builds a .ptau file with 2^power powers of tau in the snarkjs layout
+func ptauFile(power uint32, tau, alpha, beta int64) []byte {
+	n := 1 << power
+	_, _, g1, g2 := curve.Generators()
+	var tauPow fr.Element
+	tauPow.SetOne()
+	var t, a, b fr.Element
+	t.SetInt64(tau)
+	a.SetInt64(alpha)
+	b.SetInt64(beta)
+
+	var tauG1, alphaG1, betaG1 []curve.G1Affine
+	var tauG2 []curve.G2Affine
+	for i := 0; i < 2*n-1; i++ {
+		var p curve.G1Affine
+		tauG1 = append(tauG1, *p.ScalarMultiplication(&g1, scalar(tauPow)))
+		if i < n {
+			var s fr.Element
+			alphaG1 = append(alphaG1, *p.ScalarMultiplication(&g1, scalar(*s.Mul(&tauPow, &a))))
+			betaG1 = append(betaG1, *p.ScalarMultiplication(&g1, scalar(*s.Mul(&tauPow, &b))))
+			var q curve.G2Affine
+			tauG2 = append(tauG2, *q.ScalarMultiplication(&g2, scalar(tauPow)))
+		}
+		tauPow.Mul(&tauPow, &t)
+	}
+	var betaG2 curve.G2Affine
+	betaG2.ScalarMultiplication(&g2, scalar(b))
+
+	var buf bytes.Buffer
+	section := func(typ uint32, body []byte) {
+		binary.Write(&buf, binary.LittleEndian, typ)
+		binary.Write(&buf, binary.LittleEndian, uint64(len(body)))
+		buf.Write(body)
+	}
+	buf.WriteString("ptau")
+	binary.Write(&buf, binary.LittleEndian, uint32(1))
+	binary.Write(&buf, binary.LittleEndian, uint32(7))
+
+	var header bytes.Buffer
+	binary.Write(&header, binary.LittleEndian, uint32(fp.Bytes))
+	header.Write(reversed(fp.Modulus().FillBytes(make([]byte, fp.Bytes))))
+	binary.Write(&header, binary.LittleEndian, power)
+	binary.Write(&header, binary.LittleEndian, power)
+	section(ptauHeader, header.Bytes())
+	// Sections are not required to be in order
+	section(ptauBetaG2, g2Bytes(betaG2))
+	section(ptauTauG1, g1Bytes(tauG1...))
+	section(ptauTauG2, g2Bytes(tauG2...))
+	section(ptauAlphaG1, g1Bytes(alphaG1...))
+	section(ptauBetaG1, g1Bytes(betaG1...))
+	section(7, nil)
+	return buf.Bytes()
+}
+
+func scalar(e fr.Element) *big.Int {
+	return e.BigInt(new(big.Int))
+}
+
+func fpBytes(buf *bytes.Buffer, coordinates ...*fp.Element) {
+	for _, e := range coordinates {
+		for _, limb := range e {
+			binary.Write(buf, binary.LittleEndian, limb)
+		}
+	}
+}
+
+func g1Bytes(points ...curve.G1Affine) []byte {
+	var buf bytes.Buffer
+	for i := range points {
+		fpBytes(&buf, &points[i].X, &points[i].Y)
+	}
+	return buf.Bytes()
+}
+
+func g2Bytes(points ...curve.G2Affine) []byte {
+	var buf bytes.Buffer
+	for i := range points {
+		p := &points[i]
+		fpBytes(&buf, &p.X.A0, &p.X.A1, &p.Y.A0, &p.Y.A1)
+	}
+	return buf.Bytes()
+}
+
+func TestReadPtau(t *testing.T) {
+	ptau := ptauFile(3, 5, 7, 11)
+	commons, header, err := ReadPtau(bytes.NewReader(ptau), 3)
+	require.NoError(t, err)
+	require.Equal(t, uint32(3), header.Power)
+	require.Len(t, commons.G1.Tau, 7)
+	require.Len(t, commons.G1.AlphaTau, 4)
+	require.Len(t, commons.G2.Tau, 4)
+
+	var tau curve.G1Affine
+	_, _, g1, _ := curve.Generators()
+	tau.ScalarMultiplication(&g1, big.NewInt(5*5*5*5*5*5))
+	require.True(t, tau.Equal(&commons.G1.Tau[6]))
+
+	_, _, err = ReadPtau(bytes.NewReader(ptau), 9)
+	require.ErrorIs(t, err, ErrNotEnoughPowers)
+
+	// Powers of tau swapped
+	corrupted := ptauFile(3, 5, 7, 11)
+	tauG1 := bytes.Index(corrupted, g1Bytes(commons.G1.Tau[1]))
+	copy(corrupted[tauG1:], g1Bytes(commons.G1.Tau[2], commons.G1.Tau[1]))
+	_, _, err = ReadPtau(bytes.NewReader(corrupted), 3)
+	require.ErrorContains(t, err, "inconsistent")
+
+	_, _, err = ReadPtau(bytes.NewReader(ptau[:100]), 3)
+	require.Error(t, err)
+}
+
+func TestCeremonyFromPtau(t *testing.T) {
+	ccs := compile(t, &cubeCircuit{})
+	commons, _, err := ReadPtau(bytes.NewReader(ptauFile(4, 5, 7, 11)), ccs.GetNbConstraints())
+	require.NoError(t, err)
+
+	tr, err := Init(t.TempDir(), ccs, commons)
+	require.NoError(t, err)
+	_, err = tr.Contribute("alice", nil)
+	require.NoError(t, err)
+	pk, vk, _, err := tr.Seal(ccs, []byte("beacon"))
+	require.NoError(t, err)
+
+	w, err := frontend.NewWitness(&cubeCircuit{X: 2, Y: 8}, ecc.BN254.ScalarField())
+	require.NoError(t, err)
+	proof, err := groth16.Prove(ccs, pk, w)
+	require.NoError(t, err)
+	pub, err := w.Public()
+	require.NoError(t, err)
+	require.NoError(t, groth16.Verify(proof, vk, pub))
+}
@@ -0,0 +1,116 @@
+package circuits
+
+import (
+	"fmt"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bw6761"
+	"github.com/consensys/gnark/std/algebra/native/sw_bls12377"
+	"github.com/consensys/gnark/std/hash/sha2"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+	stdgroth16 "github.com/consensys/gnark/std/recursion/groth16"
+)
+
+// Proofs are aggregated over a 2-chain: inner proofs are on BLS12-377, they are verified natively by
+// AggregationCircuit on BW6-761, whose single proof is then verified by WrapCircuit on BN254 for the EVM.
+
+type (
+	innerProof     = stdgroth16.Proof[sw_bls12377.G1Affine, sw_bls12377.G2Affine]
+	innerWitness   = stdgroth16.Witness[sw_bls12377.ScalarField]
+	innerVerifyKey = stdgroth16.VerifyingKey[sw_bls12377.G1Affine, sw_bls12377.G2Affine, sw_bls12377.GT]
+
+	aggregatedProof     = stdgroth16.Proof[sw_bw6761.G1Affine, sw_bw6761.G2Affine]
+	aggregatedWitness   = stdgroth16.Witness[sw_bw6761.ScalarField]
+	aggregatedVerifyKey = stdgroth16.VerifyingKey[sw_bw6761.G1Affine, sw_bw6761.G2Affine, sw_bw6761.GTEl]
+)
+
+// AggregationCircuit verifies len(Proofs) inner proofs of the same circuit and commits to all of their public inputs.
+// Commitment is sha256 over every public input of every proof, in order, each as a 32-byte big-endian integer, split
+// into two big-endian u128 limbs. AggregateCommitment is its native counterpart.
+type AggregationCircuit struct {
+	// Fixed at compile time so that a proof can only be aggregated if it is for the expected circuit
+	VerifyingKey innerVerifyKey `gnark:"-"`
+
+	Proofs         []innerProof
+	InnerWitnesses []innerWitness
+
+	Commitment [2]frontend.Variable `gnark:",public"`
+}
+
+func (c *AggregationCircuit) Define(api frontend.API) error {
+	if len(c.Proofs) != len(c.InnerWitnesses) {
+		return fmt.Errorf("len(Proofs) %d != len(InnerWitnesses) %d", len(c.Proofs), len(c.InnerWitnesses))
+	}
+	verifier, err := stdgroth16.NewVerifier[sw_bls12377.ScalarField, sw_bls12377.G1Affine, sw_bls12377.G2Affine, sw_bls12377.GT](api)
+	if err != nil {
+		return err
+	}
+	fr, err := emulated.NewField[sw_bls12377.ScalarField](api)
+	if err != nil {
+		return err
+	}
+
+	var data []uints.U8
+	for i := range c.Proofs {
+		if err := verifier.AssertProof(c.VerifyingKey, c.Proofs[i], c.InnerWitnesses[i]); err != nil {
+			return err
+		}
+		for j := range c.InnerWitnesses[i].Public {
+			data = append(data, elementBytes32(api, fr, &c.InnerWitnesses[i].Public[j])...)
+		}
+	}
+
+	h, err := sha2.New(api)
+	if err != nil {
+		return err
+	}
+	h.Write(data)
+	digest := digestLimbs(api, u8Vals(h.Sum()))
+	api.AssertIsEqual(digest[0], c.Commitment[0])
+	api.AssertIsEqual(digest[1], c.Commitment[1])
+	return nil
+}
+
+// WrapCircuit verifies an AggregationCircuit proof and re-exposes its Commitment
+type WrapCircuit struct {
+	VerifyingKey aggregatedVerifyKey `gnark:"-"`
+
+	Proof        aggregatedProof
+	InnerWitness aggregatedWitness
+
+	Commitment [2]frontend.Variable `gnark:",public"`
+}
+
+func (c *WrapCircuit) Define(api frontend.API) error {
+	if len(c.InnerWitness.Public) != len(c.Commitment) {
+		return fmt.Errorf("aggregated proof has %d public inputs, expected %d", len(c.InnerWitness.Public), len(c.Commitment))
+	}
+	verifier, err := stdgroth16.NewVerifier[sw_bw6761.ScalarField, sw_bw6761.G1Affine, sw_bw6761.G2Affine, sw_bw6761.GTEl](api)
+	if err != nil {
+		return err
+	}
+	if err := verifier.AssertProof(c.VerifyingKey, c.Proof, c.InnerWitness); err != nil {
+		return err
+	}
+	fr, err := emulated.NewField[sw_bw6761.ScalarField](api)
+	if err != nil {
+		return err
+	}
+	for i := range c.Commitment {
+		fr.AssertIsEqual(&c.InnerWitness.Public[i], fr.FromBits(api.ToBinary(c.Commitment[i], 128)...))
+	}
+	return nil
+}
+
+// elementBytes32 returns the 32-byte big-endian encoding of el
+func elementBytes32[T emulated.FieldParams](api frontend.API, f *emulated.Field[T], el *emulated.Element[T]) []uints.U8 {
+	bits := f.ToBitsCanonical(el)
+	for len(bits) < 256 {
+		bits = append(bits, 0)
+	}
+	bs := make([]uints.U8, 32)
+	for k := range bs {
+		bs[31-k] = uints.U8{Val: api.FromBinary(bits[8*k : 8*k+8]...)}
+	}
+	return bs
+}
@@ -0,0 +1,130 @@
+package circuits
+
+import (
+	"bytes"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/solidity"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/algebra/native/sw_bls12377"
+	stdgroth16 "github.com/consensys/gnark/std/recursion/groth16"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+// squareCircuit stands in for SigVerifyCircuit, which is too large to prove in unit tests
+type squareCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+	Z frontend.Variable `gnark:",public"`
+}
+
+func (c *squareCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	api.AssertIsEqual(api.Add(c.X, 1), c.Z)
+	return nil
+}
+
+func TestAggregation(t *testing.T) {
+	const k = 2
+	inner, err := compileAndSetup(ecc.BLS12_377, &squareCircuit{})
+	require.NoError(t, err)
+	vk, err := stdgroth16.ValueOfVerifyingKeyFixed[sw_bls12377.G1Affine, sw_bls12377.G2Affine, sw_bls12377.GT](inner.vk)
+	require.NoError(t, err)
+
+	c := &AggregationCircuit{
+		VerifyingKey:   vk,
+		Proofs:         make([]innerProof, k),
+		InnerWitnesses: make([]innerWitness, k),
+	}
+	a := &AggregationCircuit{
+		Proofs:         make([]innerProof, k),
+		InnerWitnesses: make([]innerWitness, k),
+	}
+	var pubs []witness.Witness
+	for i := 0; i < k; i++ {
+		c.Proofs[i] = stdgroth16.PlaceholderProof[sw_bls12377.G1Affine, sw_bls12377.G2Affine](inner.ccs)
+		c.InnerWitnesses[i] = stdgroth16.PlaceholderWitness[sw_bls12377.ScalarField](inner.ccs)
+
+		x := i + 3
+		w, err := frontend.NewWitness(&squareCircuit{X: x, Y: x * x, Z: x + 1}, ecc.BLS12_377.ScalarField())
+		require.NoError(t, err)
+		proof, err := groth16.Prove(inner.ccs, inner.pk, w,
+			stdgroth16.GetNativeProverOptions(ecc.BW6_761.ScalarField(), ecc.BLS12_377.ScalarField()))
+		require.NoError(t, err)
+		pub, err := w.Public()
+		require.NoError(t, err)
+		pubs = append(pubs, pub)
+
+		a.Proofs[i], err = stdgroth16.ValueOfProof[sw_bls12377.G1Affine, sw_bls12377.G2Affine](proof)
+		require.NoError(t, err)
+		a.InnerWitnesses[i], err = stdgroth16.ValueOfWitness[sw_bls12377.ScalarField](pub)
+		require.NoError(t, err)
+	}
+	commitment, err := AggregateCommitment(pubs)
+	require.NoError(t, err)
+	a.Commitment = [2]frontend.Variable{commitment[0], commitment[1]}
+
+	err = test.IsSolved(c, a, ecc.BW6_761.ScalarField())
+	require.NoError(t, err)
+
+	// the commitment must cover every public input
+	a.Commitment[1] = 0
+	err = test.IsSolved(c, a, ecc.BW6_761.ScalarField())
+	require.Error(t, err)
+
+	_, err = frontend.Compile(ecc.BW6_761.ScalarField(), r1cs.NewBuilder, c)
+	require.NoError(t, err)
+}
+
+func TestAggregator(t *testing.T) {
+	const k = 2
+	a, err := NewAggregator(&squareCircuit{}, k)
+	require.NoError(t, err)
+	_, err = NewAggregator(&squareCircuit{}, 0)
+	require.Error(t, err)
+
+	var proofs []*SigVerifyProof
+	var pubs []witness.Witness
+	for i := 0; i < k; i++ {
+		x := i + 3
+		p, err := a.ProveSigVerify(&squareCircuit{X: x, Y: x * x, Z: x + 1})
+		require.NoError(t, err)
+		proofs = append(proofs, p)
+		pubs = append(pubs, p.PublicWitness)
+	}
+	_, err = a.ProveSigVerify(&squareCircuit{X: 3, Y: 10, Z: 4})
+	require.Error(t, err)
+	_, _, err = a.Aggregate(proofs[:1])
+	require.Error(t, err)
+
+	proof, commitment, err := a.Aggregate(proofs)
+	require.NoError(t, err)
+	expected, err := AggregateCommitment(pubs)
+	require.NoError(t, err)
+	require.Equal(t, expected, commitment)
+
+	// The final proof verifies on BN254 against the commitment only
+	verify := func(commitment [2]*big.Int) error {
+		pub, err := frontend.NewWitness(&WrapCircuit{Commitment: [2]frontend.Variable{commitment[0], commitment[1]}},
+			ecc.BN254.ScalarField(), frontend.PublicOnly())
+		require.NoError(t, err)
+		return groth16.Verify(proof, a.wrap.vk, pub, solidity.WithVerifierTargetSolidityVerifier(backend.GROTH16))
+	}
+	require.NoError(t, verify(commitment))
+	require.Error(t, verify([2]*big.Int{commitment[0], new(big.Int).Add(commitment[1], big.NewInt(1))}))
+
+	// A proof that does not verify is not aggregated
+	proofs[1].PublicWitness = proofs[0].PublicWitness
+	_, _, err = a.Aggregate(proofs)
+	require.Error(t, err)
+
+	var sol bytes.Buffer
+	require.NoError(t, a.ExportSolidity(&sol))
+	require.Contains(t, sol.String(), "function verifyProof(")
+}
@@ -0,0 +1,220 @@
+package circuits
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	frbls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/solidity"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bw6761"
+	"github.com/consensys/gnark/std/algebra/native/sw_bls12377"
+	stdgroth16 "github.com/consensys/gnark/std/recursion/groth16"
+	"io"
+	"math/big"
+)
+
+// SigVerifyProof is a proof of SigVerifyCircuit on BLS12-377 together with its public witness
+type SigVerifyProof struct {
+	Proof         groth16.Proof
+	PublicWitness witness.Witness
+}
+
+// Aggregator proves SigVerifyCircuit on BLS12-377 and aggregates K such proofs into a single BN254 proof that can be
+// verified by the EVM. The aggregated proof only has the two public inputs of AggregateCommitment.
+//
+// Note that CommitteeRoot of the inner proofs must be computed with CommitteeRootFor(ecc.BLS12_377, ...), which
+// NewSigVerifyAssignment does given SigVerifyParams.Curve is BLS12_377.
+type Aggregator struct {
+	K int
+
+	inner, agg, wrap groth16Keys
+	innerVk          innerVerifyKey
+	aggVk            aggregatedVerifyKey
+}
+
+type groth16Keys struct {
+	ccs constraint.ConstraintSystem
+	pk  groth16.ProvingKey
+	vk  groth16.VerifyingKey
+}
+
+// NewAggregator compiles and sets up all three circuits. inner is the placeholder of the inner circuit, SigVerifyCircuit
+// in production, k the number of proofs per aggregated proof. The setup is not suitable for production.
+func NewAggregator(inner frontend.Circuit, k int) (*Aggregator, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("invalid number of proofs to aggregate: %d", k)
+	}
+	a := &Aggregator{K: k}
+	var err error
+
+	a.inner, err = compileAndSetup(ecc.BLS12_377, inner)
+	if err != nil {
+		return nil, fmt.Errorf("inner circuit: %w", err)
+	}
+	a.innerVk, err = stdgroth16.ValueOfVerifyingKeyFixed[sw_bls12377.G1Affine, sw_bls12377.G2Affine, sw_bls12377.GT](a.inner.vk)
+	if err != nil {
+		return nil, err
+	}
+	aggCircuit := &AggregationCircuit{
+		VerifyingKey:   a.innerVk,
+		Proofs:         make([]innerProof, k),
+		InnerWitnesses: make([]innerWitness, k),
+	}
+	for i := 0; i < k; i++ {
+		aggCircuit.Proofs[i] = stdgroth16.PlaceholderProof[sw_bls12377.G1Affine, sw_bls12377.G2Affine](a.inner.ccs)
+		aggCircuit.InnerWitnesses[i] = stdgroth16.PlaceholderWitness[sw_bls12377.ScalarField](a.inner.ccs)
+	}
+	a.agg, err = compileAndSetup(ecc.BW6_761, aggCircuit)
+	if err != nil {
+		return nil, fmt.Errorf("aggregation circuit: %w", err)
+	}
+
+	a.aggVk, err = stdgroth16.ValueOfVerifyingKeyFixed[sw_bw6761.G1Affine, sw_bw6761.G2Affine, sw_bw6761.GTEl](a.agg.vk)
+	if err != nil {
+		return nil, err
+	}
+	wrapCircuit := &WrapCircuit{
+		VerifyingKey: a.aggVk,
+		Proof:        stdgroth16.PlaceholderProof[sw_bw6761.G1Affine, sw_bw6761.G2Affine](a.agg.ccs),
+		InnerWitness: stdgroth16.PlaceholderWitness[sw_bw6761.ScalarField](a.agg.ccs),
+	}
+	a.wrap, err = compileAndSetup(ecc.BN254, wrapCircuit)
+	if err != nil {
+		return nil, fmt.Errorf("wrap circuit: %w", err)
+	}
+	return a, nil
+}
+
+func compileAndSetup(curve ecc.ID, circuit frontend.Circuit) (groth16Keys, error) {
+	ccs, err := frontend.Compile(curve.ScalarField(), r1cs.NewBuilder, circuit)
+	if err != nil {
+		return groth16Keys{}, err
+	}
+	pk, vk, err := groth16.Setup(ccs)
+	if err != nil {
+		return groth16Keys{}, err
+	}
+	return groth16Keys{ccs: ccs, pk: pk, vk: vk}, nil
+}
+
+// ProveSigVerify proves one assignment of the inner circuit
+func (a *Aggregator) ProveSigVerify(assignment frontend.Circuit) (*SigVerifyProof, error) {
+	w, err := frontend.NewWitness(assignment, ecc.BLS12_377.ScalarField())
+	if err != nil {
+		return nil, err
+	}
+	proof, err := groth16.Prove(a.inner.ccs, a.inner.pk, w,
+		stdgroth16.GetNativeProverOptions(ecc.BW6_761.ScalarField(), ecc.BLS12_377.ScalarField()))
+	if err != nil {
+		return nil, err
+	}
+	pub, err := w.Public()
+	if err != nil {
+		return nil, err
+	}
+	return &SigVerifyProof{Proof: proof, PublicWitness: pub}, nil
+}
+
+// Aggregate aggregates exactly K inner proofs into one BN254 proof whose public inputs are the returned commitment
+func (a *Aggregator) Aggregate(proofs []*SigVerifyProof) (groth16.Proof, [2]*big.Int, error) {
+	var commitment [2]*big.Int
+	if len(proofs) != a.K {
+		return nil, commitment, fmt.Errorf("expected %d proofs, got %d", a.K, len(proofs))
+	}
+	pubs := make([]witness.Witness, len(proofs))
+	aggAssignment := &AggregationCircuit{
+		Proofs:         make([]innerProof, len(proofs)),
+		InnerWitnesses: make([]innerWitness, len(proofs)),
+	}
+	for i, p := range proofs {
+		err := groth16.Verify(p.Proof, a.inner.vk, p.PublicWitness,
+			stdgroth16.GetNativeVerifierOptions(ecc.BW6_761.ScalarField(), ecc.BLS12_377.ScalarField()))
+		if err != nil {
+			return nil, commitment, fmt.Errorf("invalid proof %d: %w", i, err)
+		}
+		aggAssignment.Proofs[i], err = stdgroth16.ValueOfProof[sw_bls12377.G1Affine, sw_bls12377.G2Affine](p.Proof)
+		if err != nil {
+			return nil, commitment, err
+		}
+		aggAssignment.InnerWitnesses[i], err = stdgroth16.ValueOfWitness[sw_bls12377.ScalarField](p.PublicWitness)
+		if err != nil {
+			return nil, commitment, err
+		}
+		pubs[i] = p.PublicWitness
+	}
+	commitment, err := AggregateCommitment(pubs)
+	if err != nil {
+		return nil, commitment, err
+	}
+	aggAssignment.Commitment = [2]frontend.Variable{commitment[0], commitment[1]}
+
+	aggWitness, err := frontend.NewWitness(aggAssignment, ecc.BW6_761.ScalarField())
+	if err != nil {
+		return nil, commitment, err
+	}
+	aggProof, err := groth16.Prove(a.agg.ccs, a.agg.pk, aggWitness,
+		stdgroth16.GetNativeProverOptions(ecc.BN254.ScalarField(), ecc.BW6_761.ScalarField()))
+	if err != nil {
+		return nil, commitment, fmt.Errorf("aggregation proof: %w", err)
+	}
+	aggPub, err := aggWitness.Public()
+	if err != nil {
+		return nil, commitment, err
+	}
+
+	wrapAssignment := &WrapCircuit{Commitment: aggAssignment.Commitment}
+	wrapAssignment.Proof, err = stdgroth16.ValueOfProof[sw_bw6761.G1Affine, sw_bw6761.G2Affine](aggProof)
+	if err != nil {
+		return nil, commitment, err
+	}
+	wrapAssignment.InnerWitness, err = stdgroth16.ValueOfWitness[sw_bw6761.ScalarField](aggPub)
+	if err != nil {
+		return nil, commitment, err
+	}
+	wrapWitness, err := frontend.NewWitness(wrapAssignment, ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, commitment, err
+	}
+	proof, err := groth16.Prove(a.wrap.ccs, a.wrap.pk, wrapWitness, solidity.WithProverTargetSolidityVerifier(backend.GROTH16))
+	if err != nil {
+		return nil, commitment, fmt.Errorf("wrap proof: %w", err)
+	}
+	wrapPub, err := wrapWitness.Public()
+	if err != nil {
+		return nil, commitment, err
+	}
+	err = groth16.Verify(proof, a.wrap.vk, wrapPub, solidity.WithVerifierTargetSolidityVerifier(backend.GROTH16))
+	if err != nil {
+		return nil, commitment, err
+	}
+	return proof, commitment, nil
+}
+
+// ExportSolidity writes the Solidity verifier of the aggregated proofs
+func (a *Aggregator) ExportSolidity(w io.Writer) error {
+	return a.wrap.vk.ExportSolidity(w)
+}
+
+// AggregateCommitment computes the public inputs of the aggregated proof from the public witnesses of the inner
+// proofs: sha256 over all public inputs, each as a 32-byte big-endian integer, split into two big-endian u128 limbs.
+func AggregateCommitment(pubs []witness.Witness) ([2]*big.Int, error) {
+	h := sha256.New()
+	for i, pub := range pubs {
+		vec, ok := pub.Vector().(frbls12377.Vector)
+		if !ok {
+			return [2]*big.Int{}, fmt.Errorf("public witness %d is not over BLS12-377", i)
+		}
+		for _, el := range vec {
+			b := el.Bytes()
+			h.Write(b[:])
+		}
+	}
+	digest := h.Sum(nil)
+	return [2]*big.Int{new(big.Int).SetBytes(digest[:16]), new(big.Int).SetBytes(digest[16:])}, nil
+}
@@ -0,0 +1,92 @@
+package circuits
+
+import (
+	"fmt"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+// BatchedSummary is a BCS encoded CheckpointSummary, zero padded to the max length
+type BatchedSummary struct {
+	Summary    []uints.U8
+	SummaryLen frontend.Variable
+}
+
+// BatchCheckpointCircuit proves a range of consecutive checkpoints with a single committee signature. Only the last
+// checkpoint of the range is signed, it is verified by the embedded SigVerifyCircuit. Every other checkpoint is linked
+// to its successor through the successor's previous_digest, so the whole range is as authentic as the signed one.
+type BatchCheckpointCircuit struct {
+	SigVerifyCircuit
+
+	// The checkpoints preceding the signed one, in sequence order
+	Summaries []BatchedSummary
+
+	FirstDigest         [2]frontend.Variable `gnark:",public"`
+	FirstSequenceNumber frontend.Variable    `gnark:",public"`
+	LastDigest          [2]frontend.Variable `gnark:",public"`
+	LastSequenceNumber  frontend.Variable    `gnark:",public"`
+}
+
+func (c *BatchCheckpointCircuit) Define(api frontend.API) error {
+	if err := c.SigVerifyCircuit.Define(api); err != nil {
+		return err
+	}
+	if len(c.Summaries) == 0 {
+		return fmt.Errorf("empty batch")
+	}
+
+	// the signed message is intent || bcs(CheckpointSummary) || epoch, only the bcs part is digested
+	intentLen := len(checkpointIntent)
+	lastDigest, err := checkpointDigest(api, c.CheckpointSummary[intentLen:], api.Sub(c.CheckpointSummaryLen, intentLen+8))
+	if err != nil {
+		return err
+	}
+	last := &c.Checkpoint
+
+	var digest [2]frontend.Variable
+	var seq frontend.Variable
+	for i, s := range c.Summaries {
+		checkBytes(api, s.Summary)
+		bcs := u8Vals(s.Summary)
+		assertZeroPadded(api, bcs, s.SummaryLen)
+		// decode with the same offsets as the signed message
+		msg := make([]frontend.Variable, 0, intentLen+len(bcs))
+		for _, b := range checkpointIntent {
+			msg = append(msg, b)
+		}
+		msg = append(msg, bcs...)
+		assertSummaryHeader(api, msg)
+		fields := decodeCheckpointSummary(api, msg)
+
+		d, err := checkpointDigest(api, s.Summary, s.SummaryLen)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			api.AssertIsEqual(d[0], c.FirstDigest[0])
+			api.AssertIsEqual(d[1], c.FirstDigest[1])
+			api.AssertIsEqual(fields.SequenceNumber, c.FirstSequenceNumber)
+		} else {
+			assertLinked(api, digest, seq, fields)
+		}
+		digest, seq = d, fields.SequenceNumber
+	}
+	assertLinked(api, digest, seq, last)
+
+	api.AssertIsEqual(lastDigest[0], c.LastDigest[0])
+	api.AssertIsEqual(lastDigest[1], c.LastDigest[1])
+	api.AssertIsEqual(last.SequenceNumber, c.LastSequenceNumber)
+	return nil
+}
+
+// assertLinked asserts that next directly follows the checkpoint with the given digest and sequence number
+func assertLinked(api frontend.API, digest [2]frontend.Variable, seq frontend.Variable, next *CheckpointSummaryFields) {
+	api.AssertIsEqual(next.PreviousDigest[0], digest[0])
+	api.AssertIsEqual(next.PreviousDigest[1], digest[1])
+	api.AssertIsEqual(next.SequenceNumber, api.Add(seq, 1))
+}
+
+// checkpointDigest computes the CheckpointDigest of the first length bytes of bcs as two big-endian u128 limbs
+func checkpointDigest(api frontend.API, bcs []uints.U8, length frontend.Variable) ([2]frontend.Variable, error) {
+	return suiDigest(api, "CheckpointSummary", bcs, length)
+}
@@ -0,0 +1,152 @@
+package circuits
+
+import (
+	"encoding/binary"
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+	"math/big"
+	"testing"
+)
+
+func TestBatchCheckpoint(t *testing.T) {
+	c := buildBatchCheckpointCircuit(t)
+	a := buildBatchCheckpointCircuit(t)
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(c, a, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
+
+// The range must be linked by previous_digest and have consecutive sequence numbers, both between the batched
+// checkpoints and between the last batched checkpoint and the signed one
+func TestBatchCheckpointUnlinked(t *testing.T) {
+	const seq = 134973309
+	c := buildBatchCheckpointCircuit(t)
+	for _, tc := range []struct {
+		name   string
+		seqs   []uint64
+		broken int
+	}{
+		{"broken previous digest", []uint64{seq, seq + 1, seq + 2}, 1},
+		{"broken previous digest of signed", []uint64{seq, seq + 1, seq + 2}, 2},
+		{"sequence number gap", []uint64{seq, seq + 2, seq + 3}, -1},
+		{"sequence number gap before signed", []uint64{seq, seq + 1, seq + 3}, -1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			a := buildBatch(t, tc.seqs, tc.broken)
+			require.Error(t, test.IsSolved(c, a, ecc.BN254.ScalarField()))
+		})
+	}
+}
+
+func buildBatchCheckpointCircuit(t *testing.T) *BatchCheckpointCircuit {
+	const firstSeq = 134973309
+	return buildBatch(t, []uint64{firstSeq, firstSeq + 1, firstSeq + 2}, -1)
+}
+
+// buildBatch builds a batch of checkpoints with sequence numbers seqs, only the last one is signed. If broken is
+// non-negative, the previous_digest of checkpoint broken does not match its predecessor.
+func buildBatch(t *testing.T, seqs []uint64, broken int) *BatchCheckpointCircuit {
+	const numMaxAuthorities = 4
+	const maxSummaryLen = 256
+
+	var prev [32]byte
+	var summaries []BatchedSummary
+	var firstDigest [32]byte
+	link := func(i int) [32]byte {
+		if i == broken {
+			p := prev
+			p[0] ^= 1
+			return p
+		}
+		return prev
+	}
+	for i, seq := range seqs[:len(seqs)-1] {
+		bcs := checkpointSummaryBCS(736, seq, link(i))
+		prev = checkpointDigestNative(bcs)
+		if i == 0 {
+			firstDigest = prev
+		}
+		padded := make([]byte, maxSummaryLen)
+		copy(padded, bcs)
+		summaries = append(summaries, BatchedSummary{Summary: uints.NewU8Array(padded), SummaryLen: len(bcs)})
+	}
+	lastSeq := seqs[len(seqs)-1]
+	bcs := checkpointSummaryBCS(736, lastSeq, link(len(seqs)-1))
+	lastDigest := checkpointDigestNative(bcs)
+	msg := append(append([]byte{2, 0, 0}, bcs...), binary.LittleEndian.AppendUint64(nil, 736)...)
+
+	privs, pubs := genBlsKeyPairs(3)
+	stakes := []uint64{3000, 3000, 4000}
+	signerMap := []frontend.Variable{1, 0, 1, 0}
+	msgG1, err := bls12381.HashToG1(msg, testDstG1)
+	require.NoError(t, err)
+	agg := aggSigs(signMulti(&msgG1, privs, signerMap))
+
+	pubkeys, nativePubkeys, stakeUnits, nativeStakes := padCommittee(pubs, stakes, numMaxAuthorities)
+	root, err := CommitteeRoot(nativePubkeys, nativeStakes)
+	require.NoError(t, err)
+
+	xmds0, xmds1 := expandedLimbs(t, msg)
+	padded := make([]byte, maxSummaryLen)
+	copy(padded, msg)
+
+	return &BatchCheckpointCircuit{
+		SigVerifyCircuit: SigVerifyCircuit{
+			CommitteePubKeys:           pubkeys,
+			CommitteeStakeUnits:        stakeUnits,
+			SignerMap:                  signerMap,
+			AggSig:                     sw_bls12381.NewG1Affine(*agg),
+			CheckpointSummary:          uints.NewU8Array(padded),
+			CheckpointSummaryLen:       len(msg),
+			CheckpointSummaryExpanded0: xmds0[:],
+			CheckpointSummaryExpanded1: xmds1[:],
+			CommitteeRoot:              root,
+			TotalStake:                 10000,
+			CheckpointSummaryHash:      sha256Limbs(msg),
+			Checkpoint:                 summaryFields(msg),
+		},
+		Summaries:           summaries,
+		FirstDigest:         digestLimbsNative(firstDigest),
+		FirstSequenceNumber: seqs[0],
+		LastDigest:          digestLimbsNative(lastDigest),
+		LastSequenceNumber:  lastSeq,
+	}
+}
+
+// checkpointSummaryBCS builds bcs(CheckpointSummary) of a checkpoint that is not the last of its epoch
+func checkpointSummaryBCS(epoch, seq uint64, prev [32]byte) []byte {
+	var b []byte
+	u64 := func(v uint64) { b = binary.LittleEndian.AppendUint64(b, v) }
+	u64(epoch)
+	u64(seq)
+	u64(3407759740 + seq) // network_total_transactions
+	b = append(b, 32)     // content_digest
+	for i := 0; i < 32; i++ {
+		b = append(b, byte(seq)+byte(i))
+	}
+	b = append(b, 1, 32) // previous_digest: Some
+	b = append(b, prev[:]...)
+	u64(1)                   // computation_cost
+	u64(2)                   // storage_cost
+	u64(3)                   // storage_rebate
+	u64(4)                   // non_refundable_storage_fee
+	u64(1744911576632 + seq) // timestamp_ms
+	b = append(b, 0)         // checkpoint_commitments
+	b = append(b, 0)         // end_of_epoch_data: None
+	b = append(b, 0)         // version_specific_data
+	return b
+}
+
+func checkpointDigestNative(bcs []byte) [32]byte {
+	return blake2b.Sum256(append([]byte("CheckpointSummary::"), bcs...))
+}
+
+func digestLimbsNative(d [32]byte) [2]frontend.Variable {
+	return [2]frontend.Variable{new(big.Int).SetBytes(d[:16]), new(big.Int).SetBytes(d[16:])}
+}
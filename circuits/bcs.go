@@ -0,0 +1,108 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/std/rangecheck"
+)
+
+// Helpers for reading BCS encoded bytes in circuit. Bytes are carried as uints.U8 so that they can be fed to the
+// hash gadgets directly.
+
+func checkBytes(api frontend.API, bs []uints.U8) {
+	rc := rangecheck.New(api)
+	for _, b := range bs {
+		rc.Check(b.Val, 8)
+	}
+}
+
+func u8Vals(bs []uints.U8) []frontend.Variable {
+	vals := make([]frontend.Variable, len(bs))
+	for i, b := range bs {
+		vals[i] = b.Val
+	}
+	return vals
+}
+
+// byteAt returns bs[i], or 0 if i is out of bounds
+func byteAt(bs []frontend.Variable, i int) frontend.Variable {
+	if i < 0 || i >= len(bs) {
+		return 0
+	}
+	return bs[i]
+}
+
+// leUint recomposes the little-endian unsigned integer encoded in bs
+func leUint(api frontend.API, bs []frontend.Variable) frontend.Variable {
+	v := frontend.Variable(0)
+	for i := len(bs) - 1; i >= 0; i-- {
+		v = api.Add(api.Mul(v, 256), bs[i])
+	}
+	return v
+}
+
+// beUint recomposes the big-endian unsigned integer encoded in bs
+func beUint(api frontend.API, bs []frontend.Variable) frontend.Variable {
+	v := frontend.Variable(0)
+	for _, b := range bs {
+		v = api.Add(api.Mul(v, 256), b)
+	}
+	return v
+}
+
+// digestLimbs packs a 32-byte digest into two big-endian u128 limbs
+func digestLimbs(api frontend.API, digest []frontend.Variable) [2]frontend.Variable {
+	if len(digest) != 32 {
+		panic("digest must be 32 bytes")
+	}
+	return [2]frontend.Variable{beUint(api, digest[:16]), beUint(api, digest[16:])}
+}
+
+// oneHot returns e of length n+1 where e[i] = 1 iff v == i. It asserts 0 <= v <= n.
+func oneHot(api frontend.API, v frontend.Variable, n int) []frontend.Variable {
+	e := make([]frontend.Variable, n+1)
+	sum := frontend.Variable(0)
+	for i := range e {
+		e[i] = api.IsZero(api.Sub(v, i))
+		sum = api.Add(sum, e[i])
+	}
+	api.AssertIsEqual(sum, 1)
+	return e
+}
+
+// lessThanMask returns m of length n where m[i] = 1 iff i < v. It asserts 0 <= v <= n.
+func lessThanMask(api frontend.API, v frontend.Variable, n int) []frontend.Variable {
+	eq := oneHot(api, v, n)
+	m := make([]frontend.Variable, n)
+	acc := frontend.Variable(0)
+	for i := range m {
+		acc = api.Add(acc, eq[i])
+		m[i] = api.Sub(1, acc)
+	}
+	return m
+}
+
+// assertZeroPadded asserts that all bytes of bs from index length onwards are zero and returns the mask of bytes
+// that are within length
+func assertZeroPadded(api frontend.API, bs []frontend.Variable, length frontend.Variable) []frontend.Variable {
+	mask := lessThanMask(api, length, len(bs))
+	for i, b := range bs {
+		api.AssertIsEqual(api.Mul(api.Sub(1, mask[i]), b), 0)
+	}
+	return mask
+}
+
+// assertEqualIf asserts a == b if cond is 1
+func assertEqualIf(api frontend.API, cond, a, b frontend.Variable) {
+	api.AssertIsEqual(api.Mul(cond, api.Sub(a, b)), 0)
+}
+
+// uleb128x2 decodes a ULEB128 of at most 2 bytes starting with lo. wide is 1 iff the encoding takes 2 bytes.
+func uleb128x2(api frontend.API, lo, hi frontend.Variable) (n, wide frontend.Variable) {
+	loBits := api.ToBinary(lo, 8)
+	wide = loBits[7]
+	hiBits := api.ToBinary(hi, 8)
+	api.AssertIsEqual(api.Mul(wide, hiBits[7]), 0)
+	n = api.Add(api.FromBinary(loBits[:7]...), api.Mul(wide, hi, 128))
+	return n, wide
+}
@@ -0,0 +1,153 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+const blake2bBlockSize = 128
+
+var blake2bIV = [8]uint64{
+	0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b, 0xa54ff53a5f1d36f1,
+	0x510e527fade682d1, 0x9b05688c2b3e6c1f, 0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
+}
+
+var blake2bSigma = [10][16]int{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+	{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+	{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+	{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+	{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+	{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+	{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+	{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+	{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+}
+
+// Blake2b256 computes the unkeyed Blake2b-256 digest of the first length bytes of data. data is the max-length buffer,
+// bytes from length onwards are ignored.
+func Blake2b256(api frontend.API, data []uints.U8, length frontend.Variable) ([]uints.U8, error) {
+	bf, err := uints.New[uints.U64](api)
+	if err != nil {
+		return nil, err
+	}
+
+	nbBlocks := (len(data) + blake2bBlockSize - 1) / blake2bBlockSize
+	if nbBlocks == 0 {
+		nbBlocks = 1
+	}
+	// inMsg[j] = 1 iff j < length. It also asserts length <= len(data).
+	inMsg := lessThanMask(api, length, len(data))
+	padded := make([]uints.U8, nbBlocks*blake2bBlockSize)
+	for j := range padded {
+		if j < len(data) {
+			padded[j] = uints.U8{Val: api.Mul(inMsg[j], data[j].Val)}
+		} else {
+			padded[j] = uints.NewU8(0)
+		}
+	}
+	// used[i] = 1 iff block i is compressed. The first block is always compressed, even for an empty message.
+	used := make([]frontend.Variable, nbBlocks+1)
+	used[0] = 1
+	for i := 1; i <= nbBlocks; i++ {
+		used[i] = 0
+		if j := i * blake2bBlockSize; j < len(data) {
+			used[i] = inMsg[j]
+		}
+	}
+
+	var h [8]uints.U64
+	for i := range h {
+		h[i] = uints.NewU64(blake2bIV[i])
+	}
+	// parameter block: digest length 32, no key, fanout 1, depth 1
+	h[0] = uints.NewU64(blake2bIV[0] ^ 0x01010000 ^ 32)
+
+	for i := 0; i < nbBlocks; i++ {
+		var m [16]uints.U64
+		for k := range m {
+			off := i*blake2bBlockSize + 8*k
+			m[k] = bf.PackLSB(padded[off : off+8]...)
+		}
+		isLast := api.Sub(used[i], used[i+1])
+		// counter is the number of bytes hashed so far, including this block
+		t := bf.ValueOf(api.Select(isLast, length, (i+1)*blake2bBlockSize))
+		next := blake2bCompress(api, bf, h, m, t, isLast)
+		for k := range h {
+			h[k] = selectU64(api, used[i], next[k], h[k])
+		}
+	}
+
+	digest := make([]uints.U8, 0, 32)
+	for k := 0; k < 4; k++ {
+		digest = append(digest, bf.UnpackLSB(h[k])...)
+	}
+	return digest, nil
+}
+
+func blake2bCompress(
+	api frontend.API,
+	bf *uints.BinaryField[uints.U64],
+	h [8]uints.U64,
+	m [16]uints.U64,
+	t uints.U64,
+	isLast frontend.Variable,
+) [8]uints.U64 {
+	var v [16]uints.U64
+	copy(v[:8], h[:])
+	for i := 0; i < 8; i++ {
+		v[8+i] = uints.NewU64(blake2bIV[i])
+	}
+	// the counter's high word is always 0 as messages are far shorter than 2^64 bytes
+	v[12] = bf.Xor(v[12], t)
+	v[14] = selectU64(api, isLast, uints.NewU64(^blake2bIV[6]), v[14])
+
+	g := func(a, b, c, d int, x, y uints.U64) {
+		v[a] = bf.Add(v[a], v[b], x)
+		v[d] = bf.Lrot(bf.Xor(v[d], v[a]), 64-32)
+		v[c] = bf.Add(v[c], v[d])
+		v[b] = bf.Lrot(bf.Xor(v[b], v[c]), 64-24)
+		v[a] = bf.Add(v[a], v[b], y)
+		v[d] = bf.Lrot(bf.Xor(v[d], v[a]), 64-16)
+		v[c] = bf.Add(v[c], v[d])
+		v[b] = bf.Lrot(bf.Xor(v[b], v[c]), 64-63)
+	}
+	for r := 0; r < 12; r++ {
+		s := blake2bSigma[r%10]
+		g(0, 4, 8, 12, m[s[0]], m[s[1]])
+		g(1, 5, 9, 13, m[s[2]], m[s[3]])
+		g(2, 6, 10, 14, m[s[4]], m[s[5]])
+		g(3, 7, 11, 15, m[s[6]], m[s[7]])
+		g(0, 5, 10, 15, m[s[8]], m[s[9]])
+		g(1, 6, 11, 12, m[s[10]], m[s[11]])
+		g(2, 7, 8, 13, m[s[12]], m[s[13]])
+		g(3, 4, 9, 14, m[s[14]], m[s[15]])
+	}
+
+	var out [8]uints.U64
+	for i := range out {
+		out[i] = bf.Xor(h[i], v[i], v[i+8])
+	}
+	return out
+}
+
+func selectU64(api frontend.API, sel frontend.Variable, a, b uints.U64) uints.U64 {
+	var res uints.U64
+	for k := range res {
+		res[k] = uints.U8{Val: api.Select(sel, a[k].Val, b[k].Val)}
+	}
+	return res
+}
+
+// suiDigest computes the digest Sui assigns to a value of the given type, Blake2b256("TypeName::" || bcs), over the
+// first length bytes of bcs. The digest is returned as two big-endian u128 limbs.
+func suiDigest(api frontend.API, typeName string, bcs []uints.U8, length frontend.Variable) ([2]frontend.Variable, error) {
+	prefix := []byte(typeName + "::")
+	data := append(uints.NewU8Array(prefix), bcs...)
+	digest, err := Blake2b256(api, data, api.Add(length, len(prefix)))
+	if err != nil {
+		return [2]frontend.Variable{}, err
+	}
+	return digestLimbs(api, u8Vals(digest)), nil
+}
@@ -0,0 +1,67 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+	"golang.org/x/crypto/blake2b"
+	"testing"
+)
+
+type blake2bCircuit struct {
+	Data     []uints.U8
+	DataLen  frontend.Variable
+	Expected [32]uints.U8
+}
+
+func (c *blake2bCircuit) Define(api frontend.API) error {
+	digest, err := Blake2b256(api, c.Data, c.DataLen)
+	if err != nil {
+		return err
+	}
+	for i := range digest {
+		api.AssertIsEqual(digest[i].Val, c.Expected[i].Val)
+	}
+	return nil
+}
+
+func TestBlake2b256(t *testing.T) {
+	const maxLen = 300
+	// around the block boundaries, including a last block that is completely full
+	for _, dataLen := range []int{0, 1, 127, 128, 129, 255, 256, maxLen} {
+		data := make([]byte, dataLen)
+		for i := range data {
+			data[i] = byte(i*13 + 5)
+		}
+		expected := blake2b.Sum256(data)
+		padded := make([]byte, maxLen)
+		copy(padded, data)
+		// garbage past the length must not change the digest
+		for i := dataLen; i < maxLen; i++ {
+			padded[i] = 0xff
+		}
+
+		c := &blake2bCircuit{Data: make([]uints.U8, maxLen)}
+		a := &blake2bCircuit{Data: uints.NewU8Array(padded), DataLen: dataLen}
+		copy(a.Expected[:], uints.NewU8Array(expected[:]))
+		assert := test.NewAssert(t)
+		assert.SolvingSucceeded(c, a, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+	}
+}
+
+func TestBlake2b256WrongLength(t *testing.T) {
+	const maxLen = 200
+	data := make([]byte, maxLen)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	expected := blake2b.Sum256(data[:150])
+
+	c := &blake2bCircuit{Data: make([]uints.U8, maxLen)}
+	a := &blake2bCircuit{Data: uints.NewU8Array(data), DataLen: 151}
+	copy(a.Expected[:], uints.NewU8Array(expected[:]))
+	assert := test.NewAssert(t)
+	assert.SolvingFailed(c, a, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
@@ -6,7 +6,6 @@ import (
 	"github.com/consensys/gnark-crypto/ecc"
 	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
 	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
-	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
 	"github.com/consensys/gnark/test"
@@ -21,11 +20,8 @@ func TestSimpleSig(t *testing.T) {
 	require.NoError(t, err)
 	privkey := new(big.Int).SetBytes(privkeyBytes)
 
-	c := buildSimpleSigCircuit(t, privkey)
 	a := buildSimpleSigCircuit(t, privkey)
-
-	assert := test.NewAssert(t)
-	assert.SolvingSucceeded(c, a, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BLS12_381))
+	require.NoError(t, test.IsSolved(&SimpleSigCircuit{}, a, ecc.BLS12_381.ScalarField()))
 }
 
 func buildSimpleSigCircuit(t *testing.T, privkey *big.Int) *SimpleSigCircuit {
@@ -34,10 +30,6 @@ func buildSimpleSigCircuit(t *testing.T, privkey *big.Int) *SimpleSigCircuit {
 	require.NoError(t, err)
 	h.Write(msg)
 	digest := h.Sum(nil)
-	var signingDigest []frontend.Variable
-	for _, b := range digest {
-		signingDigest = append(signingDigest, b)
-	}
 
 	_, _, _, g2Aff := bls12381.Generators()
 	pubkey := g2Aff.ScalarMultiplicationBase(privkey)
@@ -50,9 +42,9 @@ func buildSimpleSigCircuit(t *testing.T, privkey *big.Int) *SimpleSigCircuit {
 	sig.ScalarMultiplication(&xmd, privkey)
 
 	return &SimpleSigCircuit{
-		PubKey:        sw_bls12381.NewG2Affine(*pubkey),
-		AggSig:        sw_bls12381.NewG1Affine(*sig),
-		SigningDigest: signingDigest,
+		PubKey: sw_bls12381.NewG2Affine(*pubkey),
+		AggSig: sw_bls12381.NewG1Affine(*sig),
+		MsgG1:  sw_bls12381.NewG1Affine(xmd),
 	}
 }
 
@@ -60,20 +52,21 @@ type SimpleSigCircuit struct {
 	PubKey sw_bls12381.G2Affine
 	AggSig sw_bls12381.G1Affine
 
-	SigningDigest []frontend.Variable `gnark:",public"`
+	MsgG1 sw_bls12381.G1Affine `gnark:",public"`
 }
 
 func (c *SimpleSigCircuit) Define(api frontend.API) error {
-	verifySig(api, c.SigningDigest, &c.AggSig, &c.PubKey)
+	verifySig(api, &c.MsgG1, &c.AggSig, &c.PubKey)
 	return nil
 }
 
 func TestAggSig(t *testing.T) {
-	c := buildAggSigCircuit(t)
 	a := buildAggSigCircuit(t)
-
-	assert := test.NewAssert(t)
-	assert.SolvingSucceeded(c, a, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BLS12_381))
+	c := &AggSigCircuit{
+		PubKeys:   make([]sw_bls12381.G2Affine, len(a.PubKeys)),
+		SignerMap: make([]frontend.Variable, len(a.SignerMap)),
+	}
+	require.NoError(t, test.IsSolved(c, a, ecc.BLS12_381.ScalarField()))
 }
 
 func buildAggSigCircuit(t *testing.T) *AggSigCircuit {
@@ -82,10 +75,6 @@ func buildAggSigCircuit(t *testing.T) *AggSigCircuit {
 	require.NoError(t, err)
 	h.Write(msg)
 	digest := h.Sum(nil)
-	var signingDigest []frontend.Variable
-	for _, b := range digest {
-		signingDigest = append(signingDigest, b)
-	}
 
 	dstG1 := []byte("BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_NUL_")
 	xmd, err := bls12381.HashToG1(digest, dstG1)
@@ -101,10 +90,10 @@ func buildAggSigCircuit(t *testing.T) *AggSigCircuit {
 	}
 
 	return &AggSigCircuit{
-		PubKeys:       pubkeys,
-		SignerMap:     signerMap,
-		AggSig:        sw_bls12381.NewG1Affine(*agg),
-		SigningDigest: signingDigest,
+		PubKeys:   pubkeys,
+		SignerMap: signerMap,
+		AggSig:    sw_bls12381.NewG1Affine(*agg),
+		MsgG1:     sw_bls12381.NewG1Affine(xmd),
 	}
 }
 
@@ -113,12 +102,12 @@ type AggSigCircuit struct {
 	SignerMap []frontend.Variable
 	AggSig    sw_bls12381.G1Affine
 
-	SigningDigest []frontend.Variable `gnark:",public"`
+	MsgG1 sw_bls12381.G1Affine `gnark:",public"`
 }
 
 func (c *AggSigCircuit) Define(api frontend.API) error {
 	aggPub := aggPubKeys(api, c.PubKeys, c.SignerMap)
-	verifySig(api, c.SigningDigest, &c.AggSig, &aggPub)
+	verifySig(api, &c.MsgG1, &c.AggSig, &aggPub)
 	return nil
 }
 
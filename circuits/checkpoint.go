@@ -0,0 +1,89 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/sha2"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+// Layout of the message signed by the committee: intent (3 bytes) || bcs(CheckpointSummary) || epoch (u64 LE).
+// Up to checkpoint_commitments, all fields of CheckpointSummary are at fixed offsets given previous_digest is Some.
+const (
+	offsetEpoch                    = 3
+	offsetSequenceNumber           = 11
+	offsetNetworkTotalTransactions = 19
+	offsetContentDigest            = 27 // 0x20 length prefix followed by the digest
+	offsetPreviousDigest           = 60 // Option tag, 0x20 length prefix, then the digest
+	offsetGasCostSummary           = 94
+	offsetTimestampMs              = 126
+	offsetCheckpointCommitments    = 134
+
+	// CheckpointCommitment::ECMHLiveObjectSetDigest: variant || 0x20 || digest
+	checkpointCommitmentLen = 34
+	// (AuthorityPublicKeyBytes, StakeUnit): 0x60 || compressed G2 || u64
+	committeeMemberLen = 1 + 96 + 8
+)
+
+var checkpointIntent = []byte{2, 0, 0}
+
+// assertSummaryHeader asserts the intent and the length prefixes/tags of the fixed-offset part of the message
+func assertSummaryHeader(api frontend.API, msg []frontend.Variable) {
+	for i, b := range checkpointIntent {
+		api.AssertIsEqual(msg[i], b)
+	}
+	api.AssertIsEqual(msg[offsetContentDigest], 32)
+	api.AssertIsEqual(msg[offsetPreviousDigest], 1)
+	api.AssertIsEqual(msg[offsetPreviousDigest+1], 32)
+}
+
+// CheckpointSummaryFields are the fields of the signed CheckpointSummary exposed as public inputs. Digests are split
+// into two big-endian u128 limbs.
+type CheckpointSummaryFields struct {
+	Epoch                    frontend.Variable
+	SequenceNumber           frontend.Variable
+	NetworkTotalTransactions frontend.Variable
+	ContentDigest            [2]frontend.Variable
+	PreviousDigest           [2]frontend.Variable
+	TimestampMs              frontend.Variable
+}
+
+func (f *CheckpointSummaryFields) assertIsEqual(api frontend.API, other *CheckpointSummaryFields) {
+	api.AssertIsEqual(f.Epoch, other.Epoch)
+	api.AssertIsEqual(f.SequenceNumber, other.SequenceNumber)
+	api.AssertIsEqual(f.NetworkTotalTransactions, other.NetworkTotalTransactions)
+	for i := range f.ContentDigest {
+		api.AssertIsEqual(f.ContentDigest[i], other.ContentDigest[i])
+		api.AssertIsEqual(f.PreviousDigest[i], other.PreviousDigest[i])
+	}
+	api.AssertIsEqual(f.TimestampMs, other.TimestampMs)
+}
+
+// decodeCheckpointSummary decodes the fixed-offset fields of the signed message. msg must have passed
+// assertSummaryHeader.
+func decodeCheckpointSummary(api frontend.API, msg []frontend.Variable) *CheckpointSummaryFields {
+	u64 := func(offset int) frontend.Variable {
+		return leUint(api, msg[offset:offset+8])
+	}
+	digest := func(offset int) [2]frontend.Variable {
+		return digestLimbs(api, msg[offset:offset+32])
+	}
+	return &CheckpointSummaryFields{
+		Epoch:                    u64(offsetEpoch),
+		SequenceNumber:           u64(offsetSequenceNumber),
+		NetworkTotalTransactions: u64(offsetNetworkTotalTransactions),
+		ContentDigest:            digest(offsetContentDigest + 1),
+		PreviousDigest:           digest(offsetPreviousDigest + 2),
+		TimestampMs:              u64(offsetTimestampMs),
+	}
+}
+
+// sha256Digest hashes the first length bytes of msg and returns the digest as two big-endian u128 limbs
+func sha256Digest(api frontend.API, msg []uints.U8, length frontend.Variable) ([2]frontend.Variable, error) {
+	h, err := sha2.New(api)
+	if err != nil {
+		return [2]frontend.Variable{}, err
+	}
+	h.Write(msg)
+	digest := h.FixedLengthSum(length)
+	return digestLimbs(api, u8Vals(digest)), nil
+}
@@ -0,0 +1,84 @@
+package circuits
+
+import (
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	_ "github.com/consensys/gnark-crypto/ecc/bls12-377/fr/mimc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
+	_ "github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	gchash "github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/patrickmao1/zuika/sui"
+	"hash"
+	"math/big"
+)
+
+// MiMC runs over the scalar field of the curve the circuit is compiled for, so the root depends on it
+var committeeRootHashes = map[ecc.ID]gchash.Hash{
+	ecc.BN254:     gchash.MIMC_BN254,
+	ecc.BLS12_377: gchash.MIMC_BLS12_377,
+}
+
+// CommitteeRoot computes the same root as commitPubKeys does in circuit. pubkeys and stakes must already be padded
+// to the circuit's number of max authorities (infinity pubkeys with zero stake).
+func CommitteeRoot(pubkeys []bls12381.G2Affine, stakes []uint64) (*big.Int, error) {
+	return CommitteeRootFor(ecc.BN254, pubkeys, stakes)
+}
+
+// CommitteeRootFor is CommitteeRoot for a circuit compiled over the scalar field of curve
+func CommitteeRootFor(curve ecc.ID, pubkeys []bls12381.G2Affine, stakes []uint64) (*big.Int, error) {
+	if len(pubkeys) != len(stakes) {
+		return nil, fmt.Errorf("len(pubkeys) %d != len(stakes) %d", len(pubkeys), len(stakes))
+	}
+	hf, ok := committeeRootHashes[curve]
+	if !ok {
+		return nil, fmt.Errorf("unsupported curve %s", curve)
+	}
+	h := hf.New()
+	for i := range pubkeys {
+		pubkey := &pubkeys[i]
+		for _, el := range []*fp.Element{&pubkey.X.A0, &pubkey.X.A1, &pubkey.Y.A0, &pubkey.Y.A1} {
+			for _, limb := range fpLimbs(el) {
+				if err := writeMiMC(h, limb); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if err := writeMiMC(h, new(big.Int).SetUint64(stakes[i])); err != nil {
+			return nil, err
+		}
+	}
+	return new(big.Int).SetBytes(h.Sum(nil)), nil
+}
+
+// fpLimbs splits el into the little-endian limbs gnark uses for an emulated BLS12-381 base field element
+func fpLimbs(el *fp.Element) []*big.Int {
+	var params emulated.BLS12381Fp
+	nbLimbs, bitsPerLimb := params.NbLimbs(), params.BitsPerLimb()
+
+	v := el.BigInt(new(big.Int))
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), bitsPerLimb), big.NewInt(1))
+	limbs := make([]*big.Int, nbLimbs)
+	for i := range limbs {
+		limbs[i] = new(big.Int).And(v, mask)
+		v.Rsh(v, bitsPerLimb)
+	}
+	return limbs
+}
+
+// writeMiMC writes v as a 32-byte big-endian field element. v is at most a 64-bit limb so it needs no reduction.
+func writeMiMC(h hash.Hash, v *big.Int) error {
+	_, err := h.Write(v.FillBytes(make([]byte, 32)))
+	return err
+}
+
+// SuiCommitteeRoot is CommitteeRoot of committee padded to maxAuthorities, the CommitteeRoot public input of
+// SigVerifyCircuit and the root ZKLightClient must be deployed with
+func SuiCommitteeRoot(committee *sui.Committee, maxAuthorities int) (*big.Int, error) {
+	pubkeys, stakes, err := PadCommittee(committee, maxAuthorities)
+	if err != nil {
+		return nil, err
+	}
+	return CommitteeRoot(pubkeys, stakes)
+}
@@ -0,0 +1,57 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type committeeRootCircuit struct {
+	PubKeys []sw_bls12381.G2Affine
+	Stakes  []frontend.Variable
+	Root    frontend.Variable `gnark:",public"`
+}
+
+func (c *committeeRootCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(commitPubKeys(api, c.PubKeys, c.Stakes), c.Root)
+	return nil
+}
+
+func TestCommitteeRoot(t *testing.T) {
+	const numMaxAuthorities = 5
+	_, pubs := genBlsKeyPairs(3)
+	stakes := []uint64{3000, 3000, 4000}
+	pubkeys, nativePubkeys, stakeUnits, nativeStakes := padCommittee(pubs, stakes, numMaxAuthorities)
+
+	assert := test.NewAssert(t)
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_377} {
+		root, err := CommitteeRootFor(curve, nativePubkeys, nativeStakes)
+		require.NoError(t, err)
+
+		c := &committeeRootCircuit{PubKeys: pubkeys, Stakes: stakeUnits}
+		a := &committeeRootCircuit{PubKeys: pubkeys, Stakes: stakeUnits, Root: root}
+		assert.SolvingSucceeded(c, a, test.WithBackends(backend.GROTH16), test.WithCurves(curve))
+
+		// the root binds the order of authorities and their stakes
+		swapped := append([]frontend.Variable{}, stakeUnits...)
+		swapped[0], swapped[2] = swapped[2], swapped[0]
+		a = &committeeRootCircuit{PubKeys: pubkeys, Stakes: swapped, Root: root}
+		assert.SolvingFailed(c, a, test.WithBackends(backend.GROTH16), test.WithCurves(curve))
+	}
+
+	bn254Root, err := CommitteeRoot(nativePubkeys, nativeStakes)
+	require.NoError(t, err)
+	bls12377Root, err := CommitteeRootFor(ecc.BLS12_377, nativePubkeys, nativeStakes)
+	require.NoError(t, err)
+	require.NotEqual(t, bn254Root, bls12377Root)
+
+	_, err = CommitteeRoot(nativePubkeys, nativeStakes[1:])
+	require.Error(t, err)
+	_, err = CommitteeRootFor(ecc.BW6_761, []bls12381.G2Affine{}, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,172 @@
+package circuits
+
+import (
+	"fmt"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/fields_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/math/emulated"
+	"math/big"
+	"slices"
+)
+
+const DefaultMaxCheckpointCommitments = 1
+
+// CommitteeRotationCircuit verifies an end-of-epoch checkpoint against the current CommitteeRoot and outputs the root
+// of the next epoch's committee parsed from end_of_epoch_data.next_epoch_committee. The next root is computed the
+// same way as commitPubKeys so that it can be used as the CommitteeRoot of the next epoch's SigVerifyCircuit.
+type CommitteeRotationCircuit struct {
+	SigVerifyCircuit
+
+	// Max number of checkpoint_commitments preceding end_of_epoch_data. Zero falls back to
+	// DefaultMaxCheckpointCommitments.
+	MaxCheckpointCommitments int `gnark:"-"`
+
+	// Uncompressed public keys of next_epoch_committee in committee order, padded with infinity to
+	// len(CommitteePubKeys)
+	NextCommitteePubKeys []sw_bls12381.G2Affine
+
+	NextCommitteeRoot frontend.Variable `gnark:",public"`
+}
+
+func (c *CommitteeRotationCircuit) Define(api frontend.API) error {
+	if err := c.SigVerifyCircuit.Define(api); err != nil {
+		return err
+	}
+	if len(c.NextCommitteePubKeys) != len(c.CommitteePubKeys) {
+		return fmt.Errorf("len(NextCommitteePubKeys) %d != len(CommitteePubKeys) %d",
+			len(c.NextCommitteePubKeys), len(c.CommitteePubKeys))
+	}
+
+	// CheckpointSummary is already bound to the signature and its header checked by SigVerifyCircuit
+	msg := u8Vals(c.CheckpointSummary)
+	stakes, err := c.nextEpochCommittee(api, msg)
+	if err != nil {
+		return err
+	}
+	nextRoot := commitPubKeys(api, c.NextCommitteePubKeys, stakes)
+	api.AssertIsEqual(nextRoot, c.NextCommitteeRoot)
+	return nil
+}
+
+func (c *CommitteeRotationCircuit) maxCheckpointCommitments() int {
+	if c.MaxCheckpointCommitments == 0 {
+		return DefaultMaxCheckpointCommitments
+	}
+	return c.MaxCheckpointCommitments
+}
+
+// nextEpochCommittee checks NextCommitteePubKeys against the compressed public keys in next_epoch_committee and
+// returns the parsed stake units, zero for padding slots.
+func (c *CommitteeRotationCircuit) nextEpochCommittee(api frontend.API, msg []frontend.Variable) ([]frontend.Variable, error) {
+	maxAuthorities := len(c.NextCommitteePubKeys)
+
+	// end_of_epoch_data comes right after checkpoint_commitments, whose length decides by how much it is shifted
+	nbCommitments := msg[offsetCheckpointCommitments]
+	commitmentSel := oneHot(api, nbCommitments, c.maxCheckpointCommitments())
+	eoe := func(i int) frontend.Variable {
+		v := frontend.Variable(0)
+		for k, sel := range commitmentSel {
+			v = api.Add(v, api.Mul(sel, byteAt(msg, offsetCheckpointCommitments+1+k*checkpointCommitmentLen+i)))
+		}
+		return v
+	}
+	// end_of_epoch_data must be Some
+	api.AssertIsEqual(eoe(0), 1)
+
+	// the length of next_epoch_committee is a ULEB128 of at most 2 bytes
+	n, wide := uleb128x2(api, eoe(1), eoe(2))
+	active := lessThanMask(api, n, maxAuthorities)
+
+	member := func(i int) frontend.Variable {
+		return api.Select(wide, eoe(3+i), eoe(2+i))
+	}
+
+	fp, err := emulated.NewField[emulated.BLS12381Fp](api)
+	if err != nil {
+		return nil, err
+	}
+	e2 := fields_bls12381.NewExt2(api)
+
+	stakes := make([]frontend.Variable, maxAuthorities)
+	for i := range stakes {
+		entry := make([]frontend.Variable, committeeMemberLen)
+		for j := range entry {
+			entry[j] = member(i*committeeMemberLen + j)
+		}
+		assertEqualIf(api, active[i], entry[0], 96)
+		assertCompressedG2(api, fp, e2, &c.NextCommitteePubKeys[i], entry[1:97], active[i])
+		stakes[i] = api.Mul(active[i], leUint(api, entry[97:]))
+	}
+	return stakes, nil
+}
+
+// assertCompressedG2 asserts that pk is the point encoded by compressed (96 bytes, zcash format) if active is 1, and
+// that pk is the all-zero infinity placeholder otherwise.
+func assertCompressedG2(
+	api frontend.API,
+	fp *emulated.Field[emulated.BLS12381Fp],
+	e2 *fields_bls12381.Ext2,
+	pk *sw_bls12381.G2Affine,
+	compressed []frontend.Variable,
+	active frontend.Variable,
+) {
+	inactive := api.Sub(1, active)
+	for _, el := range []*emulated.Element[emulated.BLS12381Fp]{&pk.P.X.A0, &pk.P.X.A1, &pk.P.Y.A0, &pk.P.Y.A1} {
+		for _, limb := range el.Limbs {
+			api.AssertIsEqual(api.Mul(inactive, limb), 0)
+		}
+	}
+
+	// The limbs of Y are hashed into the next root as is, so Y must be canonical: Y+p would pass the curve equation
+	// and the sign check but yield a root no native CommitteeRoot matches. X is pinned to the compressed bytes below.
+	fp.AssertIsInRange(&pk.P.Y.A0)
+	fp.AssertIsInRange(&pk.P.Y.A1)
+
+	// X.A1 || X.A0, big-endian. The 3 most significant bits of the first byte are flags.
+	x := append(elementBytes(api, &pk.P.X.A1), elementBytes(api, &pk.P.X.A0)...)
+	for i := 1; i < len(x); i++ {
+		assertEqualIf(api, active, x[i], compressed[i])
+	}
+	topBits := api.ToBinary(x[0], 8)
+	for _, b := range topBits[5:] {
+		api.AssertIsEqual(b, 0)
+	}
+	// compression flag 0x80, sign flag 0x20 set iff Y is lexicographically the largest
+	sign := lexicographicallyLargest(api, fp, &pk.P.Y)
+	assertEqualIf(api, active, api.Add(0x80, api.Mul(sign, 0x20), x[0]), compressed[0])
+
+	// Y is only determined by X up to its sign if the point is on the curve: y² = x³ + 4(1+u)
+	b := fields_bls12381.E2{
+		A0: emulated.ValueOf[emulated.BLS12381Fp](4),
+		A1: emulated.ValueOf[emulated.BLS12381Fp](4),
+	}
+	y2 := e2.Square(&pk.P.Y)
+	x3 := e2.Mul(e2.Square(&pk.P.X), &pk.P.X)
+	diff := e2.Sub(y2, e2.Add(x3, &b))
+	e2.AssertIsEqual(e2.Select(active, diff, e2.Zero()), e2.Zero())
+}
+
+// elementBytes returns the 48-byte big-endian encoding of el from its 64-bit little-endian limbs
+func elementBytes(api frontend.API, el *emulated.Element[emulated.BLS12381Fp]) []frontend.Variable {
+	bs := make([]frontend.Variable, 0, 48)
+	for _, limb := range el.Limbs {
+		bits := api.ToBinary(limb, 64)
+		for k := 0; k < 8; k++ {
+			bs = append(bs, api.FromBinary(bits[8*k:8*k+8]...))
+		}
+	}
+	slices.Reverse(bs)
+	return bs
+}
+
+// lexicographicallyLargest mirrors bls12381.E2.LexicographicallyLargest: the sign is taken from A1 unless it is zero
+func lexicographicallyLargest(api frontend.API, fp *emulated.Field[emulated.BLS12381Fp], y *fields_bls12381.E2) frontend.Variable {
+	return api.Select(fp.IsZero(&y.A1), isLargest(fp, &y.A0), isLargest(fp, &y.A1))
+}
+
+// isLargest returns 1 iff y > (p-1)/2, which is the case iff 2y mod p is odd
+func isLargest(fp *emulated.Field[emulated.BLS12381Fp], y *emulated.Element[emulated.BLS12381Fp]) frontend.Variable {
+	bits := fp.ToBitsCanonical(fp.MulConst(y, big.NewInt(2)))
+	return bits[0]
+}
@@ -0,0 +1,218 @@
+package circuits
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
+	"github.com/consensys/gnark-crypto/field/hash"
+	gchash "github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+	"math"
+	"math/big"
+	"testing"
+)
+
+var testDstG1 = []byte("BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_NUL_")
+
+func TestCommitteeRotation(t *testing.T) {
+	c := buildCommitteeRotationCircuit(t)
+	a := buildCommitteeRotationCircuit(t)
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(c, a, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
+
+// A next committee key with Y.A0 + p still satisfies the curve equation and the sign check, but must not be accepted
+// since the root over its limbs matches no native CommitteeRoot
+func TestCommitteeRotationNonCanonicalY(t *testing.T) {
+	c := buildCommitteeRotationCircuit(t)
+	a, nextPubkeys, nextStakes := buildCommitteeRotation(t)
+
+	y := nextPubkeys[0].Y.A0.BigInt(new(big.Int))
+	y.Add(y, fp.Modulus())
+	yLimbs := make([]frontend.Variable, 6)
+	h := gchash.MIMC_BN254.New()
+	for i := range nextPubkeys {
+		for j, el := range []*fp.Element{&nextPubkeys[i].X.A0, &nextPubkeys[i].X.A1, &nextPubkeys[i].Y.A0, &nextPubkeys[i].Y.A1} {
+			limbs := fpLimbs(el)
+			if i == 0 && j == 2 {
+				mask := new(big.Int).SetUint64(math.MaxUint64)
+				for k := range limbs {
+					limbs[k] = new(big.Int).And(new(big.Int).Rsh(y, uint(64*k)), mask)
+					yLimbs[k] = limbs[k]
+				}
+			}
+			for _, limb := range limbs {
+				require.NoError(t, writeMiMC(h, limb))
+			}
+		}
+		require.NoError(t, writeMiMC(h, new(big.Int).SetUint64(nextStakes[i])))
+	}
+	a.NextCommitteePubKeys[0].P.Y.A0 = emulated.Element[emulated.BLS12381Fp]{Limbs: yLimbs}
+	a.NextCommitteeRoot = new(big.Int).SetBytes(h.Sum(nil))
+	require.Error(t, test.IsSolved(c, a, ecc.BN254.ScalarField()))
+}
+
+func buildCommitteeRotationCircuit(t *testing.T) *CommitteeRotationCircuit {
+	c, _, _ := buildCommitteeRotation(t)
+	return c
+}
+
+// buildCommitteeRotation also returns the padded next committee
+func buildCommitteeRotation(t *testing.T) (*CommitteeRotationCircuit, []bls12381.G2Affine, []uint64) {
+	const numMaxAuthorities = 4
+	const maxMsgLen = 1024
+
+	privs, pubs := genBlsKeyPairs(3)
+	stakes := []uint64{3000, 3000, 4000}
+	signerMap := []frontend.Variable{1, 0, 1, 0}
+
+	_, nextPubs := genBlsKeyPairs(2)
+	nextStakes := []uint64{6000, 4000}
+
+	msg := endOfEpochMessage(736, nextPubs, nextStakes)
+	msgG1, err := bls12381.HashToG1(msg, testDstG1)
+	require.NoError(t, err)
+	agg := aggSigs(signMulti(&msgG1, privs, signerMap))
+
+	pubkeys, nativePubkeys, stakeUnits, nativeStakes := padCommittee(pubs, stakes, numMaxAuthorities)
+	root, err := CommitteeRoot(nativePubkeys, nativeStakes)
+	require.NoError(t, err)
+	nextPubkeys, nextNativePubkeys, _, nextNativeStakes := padCommittee(nextPubs, nextStakes, numMaxAuthorities)
+	nextRoot, err := CommitteeRoot(nextNativePubkeys, nextNativeStakes)
+	require.NoError(t, err)
+
+	xmds0, xmds1 := expandedLimbs(t, msg)
+	padded := make([]byte, maxMsgLen)
+	copy(padded, msg)
+
+	return &CommitteeRotationCircuit{
+		SigVerifyCircuit: SigVerifyCircuit{
+			CommitteePubKeys:           pubkeys,
+			CommitteeStakeUnits:        stakeUnits,
+			SignerMap:                  signerMap,
+			AggSig:                     sw_bls12381.NewG1Affine(*agg),
+			CheckpointSummary:          uints.NewU8Array(padded),
+			CheckpointSummaryLen:       len(msg),
+			CheckpointSummaryExpanded0: xmds0[:],
+			CheckpointSummaryExpanded1: xmds1[:],
+			CommitteeRoot:              root,
+			TotalStake:                 10000,
+			CheckpointSummaryHash:      sha256Limbs(msg),
+			Checkpoint:                 summaryFields(msg),
+		},
+		NextCommitteePubKeys: nextPubkeys,
+		NextCommitteeRoot:    nextRoot,
+	}, nextNativePubkeys, nextNativeStakes
+}
+
+func sha256Limbs(msg []byte) [2]frontend.Variable {
+	digest := sha256.Sum256(msg)
+	return [2]frontend.Variable{new(big.Int).SetBytes(digest[:16]), new(big.Int).SetBytes(digest[16:])}
+}
+
+// summaryFields natively decodes what decodeCheckpointSummary decodes in circuit
+func summaryFields(msg []byte) CheckpointSummaryFields {
+	u64 := func(offset int) frontend.Variable {
+		return binary.LittleEndian.Uint64(msg[offset:])
+	}
+	digest := func(offset int) [2]frontend.Variable {
+		return [2]frontend.Variable{
+			new(big.Int).SetBytes(msg[offset : offset+16]),
+			new(big.Int).SetBytes(msg[offset+16 : offset+32]),
+		}
+	}
+	return CheckpointSummaryFields{
+		Epoch:                    u64(offsetEpoch),
+		SequenceNumber:           u64(offsetSequenceNumber),
+		NetworkTotalTransactions: u64(offsetNetworkTotalTransactions),
+		ContentDigest:            digest(offsetContentDigest + 1),
+		PreviousDigest:           digest(offsetPreviousDigest + 2),
+		TimestampMs:              u64(offsetTimestampMs),
+	}
+}
+
+// endOfEpochMessage builds the signed message of an end-of-epoch checkpoint:
+// intent || bcs(CheckpointSummary) || epoch
+func endOfEpochMessage(epoch uint64, nextPubs []*bls12381.G2Affine, nextStakes []uint64) []byte {
+	var b []byte
+	u64 := func(v uint64) { b = binary.LittleEndian.AppendUint64(b, v) }
+	digest := func(fill byte) {
+		b = append(b, 32)
+		for i := 0; i < 32; i++ {
+			b = append(b, fill)
+		}
+	}
+
+	b = append(b, 2, 0, 0)
+	u64(epoch)
+	u64(134973309)     // sequence_number
+	u64(3407759740)    // network_total_transactions
+	digest(0xaa)       // content_digest
+	b = append(b, 1)   // previous_digest: Some
+	digest(0xbb)       // previous_digest
+	u64(1)             // computation_cost
+	u64(2)             // storage_cost
+	u64(3)             // storage_rebate
+	u64(4)             // non_refundable_storage_fee
+	u64(1744911576632) // timestamp_ms
+	b = append(b, 0)   // checkpoint_commitments
+	b = append(b, 1)   // end_of_epoch_data: Some
+	b = append(b, byte(len(nextPubs)))
+	for i, pub := range nextPubs {
+		pb := pub.Bytes()
+		b = append(b, 96)
+		b = append(b, pb[:]...)
+		u64(nextStakes[i])
+	}
+	u64(70)          // next_epoch_protocol_version
+	b = append(b, 0) // epoch_commitments
+	b = append(b, 0) // version_specific_data
+	u64(epoch)
+	return b
+}
+
+func padCommittee(pubs []*bls12381.G2Affine, stakes []uint64, numMaxAuthorities int) (
+	pubkeys []sw_bls12381.G2Affine,
+	nativePubkeys []bls12381.G2Affine,
+	stakeUnits []frontend.Variable,
+	nativeStakes []uint64,
+) {
+	pubkeys = make([]sw_bls12381.G2Affine, numMaxAuthorities)
+	nativePubkeys = make([]bls12381.G2Affine, numMaxAuthorities)
+	stakeUnits = make([]frontend.Variable, numMaxAuthorities)
+	nativeStakes = make([]uint64, numMaxAuthorities)
+	for i := range pubkeys {
+		if i < len(pubs) {
+			nativePubkeys[i] = *pubs[i]
+			nativeStakes[i] = stakes[i]
+		} else {
+			nativePubkeys[i].SetInfinity()
+		}
+		pubkeys[i] = sw_bls12381.NewG2Affine(nativePubkeys[i])
+		stakeUnits[i] = nativeStakes[i]
+	}
+	return
+}
+
+func expandedLimbs(t *testing.T, msg []byte) (xmds0, xmds1 [3]frontend.Variable) {
+	xmd, err := hash.ExpandMsgXmd(msg, testDstG1, 128)
+	require.NoError(t, err)
+	for i, half := range [][]byte{xmd[:64], xmd[64:]} {
+		limbs := &xmds0
+		if i == 1 {
+			limbs = &xmds1
+		}
+		limbs[0] = new(big.Int).SetBytes(half[:2])
+		limbs[1] = new(big.Int).SetBytes(half[2:33])
+		limbs[2] = new(big.Int).SetBytes(half[33:])
+	}
+	return
+}
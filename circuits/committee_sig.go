@@ -7,27 +7,54 @@ import (
 	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
 	"github.com/consensys/gnark/std/hash/mimc"
 	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
 	"github.com/consensys/gnark/std/rangecheck"
 	"math/big"
 	"slices"
 )
 
+// Default quorum rule: more than 2/3 of the committee's total stake must have signed
+const (
+	DefaultQuorumNumerator   = 2
+	DefaultQuorumDenominator = 3
+)
+
 type SigVerifyCircuit struct {
 	api    frontend.API
 	g1     *sw_bls12381.G1
 	curveF *emulated.Field[emulated.BLS12381Fp]
 
+	// Circuit parameters. The quorum is reached iff
+	// signedStake * QuorumDenominator > TotalStake * QuorumNumerator.
+	// Zero values fall back to DefaultQuorumNumerator and DefaultQuorumDenominator.
+	QuorumNumerator   int `gnark:"-"`
+	QuorumDenominator int `gnark:"-"`
+	// If set, expand_message_xmd is computed in circuit from CheckpointSummary and CheckpointSummaryExpanded0/1 must
+	// be left empty: only CheckpointSummaryHash is needed to bind the message. Otherwise the contract computes
+	// expand_message_xmd and CheckpointSummaryExpanded0/1 hold 3 limbs each.
+	ExpandInCircuit bool `gnark:"-"`
+
 	CommitteePubKeys    []sw_bls12381.G2Affine
 	CommitteeStakeUnits []frontend.Variable
 	SignerMap           []frontend.Variable // bits
 	AggSig              sw_bls12381.G1Affine
+	// The signed message intent || bcs(CheckpointSummary) || epoch, zero padded to a fixed max length
+	CheckpointSummary    []uints.U8
+	CheckpointSummaryLen frontend.Variable
 
 	// expandMessageXmd output is 128 byte. split in middle and distributed each 64-byte slice to
 	// 3 u248 limbs
 	// 64 bytes
-	CheckpointSummaryExpanded0 [3]frontend.Variable `gnark:",public"`
-	CheckpointSummaryExpanded1 [3]frontend.Variable `gnark:",public"`
-	CommitteeRoot              frontend.Variable    `gnark:",public"`
+	CheckpointSummaryExpanded0 []frontend.Variable `gnark:",public"`
+	CheckpointSummaryExpanded1 []frontend.Variable `gnark:",public"`
+	CommitteeRoot              frontend.Variable   `gnark:",public"`
+	// sum of CommitteeStakeUnits
+	TotalStake frontend.Variable `gnark:",public"`
+	// sha256 of the signed message as two big-endian u128 limbs. The contract computes it over the same bytes it
+	// expands for CheckpointSummaryExpanded0/1, which binds CheckpointSummary to the signature.
+	CheckpointSummaryHash [2]frontend.Variable `gnark:",public"`
+	// Fields decoded from CheckpointSummary
+	Checkpoint CheckpointSummaryFields `gnark:",public"`
 }
 
 func (c *SigVerifyCircuit) Define(api frontend.API) error {
@@ -48,14 +75,36 @@ func (c *SigVerifyCircuit) Define(api frontend.API) error {
 		rc.Check(b, 1)
 	}
 
+	totalStake := totalStakeUnits(api, c.CommitteeStakeUnits)
+	api.AssertIsEqual(totalStake, c.TotalStake)
 	signedStake := signedStakeUnits(api, c.CommitteeStakeUnits, c.SignerMap)
-	api.AssertIsLessOrEqual(6667, signedStake)
+	num, den, err := quorum(c.QuorumNumerator, c.QuorumDenominator)
+	if err != nil {
+		return err
+	}
+	assertQuorum(api, signedStake, totalStake, num, den)
 
-	committeeRoot := commitPubKeys(api, c.CommitteePubKeys)
+	committeeRoot := commitPubKeys(api, c.CommitteePubKeys, c.CommitteeStakeUnits)
 	api.AssertIsEqual(committeeRoot, c.CommitteeRoot)
 
-	el0 := c.u248LimbsToElement(c.CheckpointSummaryExpanded0)
-	el1 := c.u248LimbsToElement(c.CheckpointSummaryExpanded1)
+	checkBytes(api, c.CheckpointSummary)
+	msg := u8Vals(c.CheckpointSummary)
+	assertZeroPadded(api, msg, c.CheckpointSummaryLen)
+	assertSummaryHeader(api, msg)
+	digest, err := sha256Digest(api, c.CheckpointSummary, c.CheckpointSummaryLen)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(digest[0], c.CheckpointSummaryHash[0])
+	api.AssertIsEqual(digest[1], c.CheckpointSummaryHash[1])
+	decodeCheckpointSummary(api, msg).assertIsEqual(api, &c.Checkpoint)
+
+	expanded0, expanded1, err := c.expandedLimbs(api)
+	if err != nil {
+		return err
+	}
+	el0 := c.u248LimbsToElement(expanded0)
+	el1 := c.u248LimbsToElement(expanded1)
 	q0, err := c.g1.MapToG1(el0)
 	if err != nil {
 		return err
@@ -66,13 +115,44 @@ func (c *SigVerifyCircuit) Define(api frontend.API) error {
 	}
 	chkG1 := c.addG1(q0, q1)
 
-	fmt.Printf("chkG1: %x %x\n", chkG1.X.Limbs, chkG1.Y.Limbs)
 	aggPubkey := aggPubKeys(api, c.CommitteePubKeys, c.SignerMap)
 	verifySig(api, chkG1, &c.AggSig, &aggPubkey)
 
 	return nil
 }
 
+// quorum returns the quorum rule num/den, zero values falling back to the default one
+func quorum(num, den int) (int, int, error) {
+	if num == 0 && den == 0 {
+		return DefaultQuorumNumerator, DefaultQuorumDenominator, nil
+	}
+	if num <= 0 || den <= num {
+		return 0, 0, fmt.Errorf("invalid quorum %d/%d", num, den)
+	}
+	return num, den, nil
+}
+
+// expandedLimbs returns the expand_message_xmd output of the signed message, either computed in circuit or taken
+// from the public inputs
+func (c *SigVerifyCircuit) expandedLimbs(api frontend.API) (l0, l1 [3]frontend.Variable, err error) {
+	if c.ExpandInCircuit {
+		if len(c.CheckpointSummaryExpanded0) != 0 || len(c.CheckpointSummaryExpanded1) != 0 {
+			return l0, l1, fmt.Errorf("CheckpointSummaryExpanded0/1 must be empty when ExpandInCircuit is set")
+		}
+		xmd, err := expandMsgXmd(api, c.CheckpointSummary, c.CheckpointSummaryLen, blsSigDst, 128)
+		if err != nil {
+			return l0, l1, err
+		}
+		return xmdToU248Limbs(api, xmd[:64]), xmdToU248Limbs(api, xmd[64:]), nil
+	}
+	if len(c.CheckpointSummaryExpanded0) != 3 || len(c.CheckpointSummaryExpanded1) != 3 {
+		return l0, l1, fmt.Errorf("CheckpointSummaryExpanded0/1 must have 3 limbs each")
+	}
+	copy(l0[:], c.CheckpointSummaryExpanded0)
+	copy(l1[:], c.CheckpointSummaryExpanded1)
+	return l0, l1, nil
+}
+
 func (c *SigVerifyCircuit) fieldsToG1(a, b [3]frontend.Variable) (*sw_bls12381.G1Affine, error) {
 	g1a, err := c.fieldToG1(a)
 	if err != nil {
@@ -156,6 +236,19 @@ func signedStakeUnits(api frontend.API, stakes, signerMap []frontend.Variable) f
 	return signedStake
 }
 
+func totalStakeUnits(api frontend.API, stakes []frontend.Variable) frontend.Variable {
+	total := frontend.Variable(0)
+	for _, stake := range stakes {
+		total = api.Add(total, stake)
+	}
+	return total
+}
+
+// assertQuorum asserts signed * den > total * num
+func assertQuorum(api frontend.API, signed, total frontend.Variable, num, den int) {
+	api.AssertIsLessOrEqual(api.Add(api.Mul(total, num), 1), api.Mul(signed, den))
+}
+
 func verifySig(api frontend.API, msgG1 *sw_bls12381.G1Affine, sig *sw_bls12381.G1Affine, pubkey *sw_bls12381.G2Affine) {
 	_, _, _, g2GenNative := bls12381.Generators()
 	g2Gen := sw_bls12381.NewG2Affine(g2GenNative)
@@ -179,14 +272,23 @@ func verifySig(api frontend.API, msgG1 *sw_bls12381.G1Affine, sig *sw_bls12381.G
 	pairing.AssertIsEqual(lhs, rhs)
 }
 
-func commitPubKeys(api frontend.API, pubkeys []sw_bls12381.G2Affine) frontend.Variable {
+// commitPubKeys hashes the committee into a single root. For each authority, in committee order, the limbs of the
+// full G2 public key (X.A0, X.A1, Y.A0, Y.A1) are written followed by its stake units. CommitteeRoot is the native
+// counterpart of this function.
+func commitPubKeys(api frontend.API, pubkeys []sw_bls12381.G2Affine, stakes []frontend.Variable) frontend.Variable {
+	if len(pubkeys) != len(stakes) {
+		panic("len(pubkeys) != len(stakes)")
+	}
 	h, err := mimc.NewMiMC(api)
 	if err != nil {
 		panic(err)
 	}
-	for _, pubkey := range pubkeys {
+	for i, pubkey := range pubkeys {
 		h.Write(pubkey.P.X.A0.Limbs...)
 		h.Write(pubkey.P.X.A1.Limbs...)
+		h.Write(pubkey.P.Y.A0.Limbs...)
+		h.Write(pubkey.P.Y.A1.Limbs...)
+		h.Write(stakes[i])
 	}
 	return h.Sum()
 }
@@ -0,0 +1,75 @@
+package circuits
+
+import (
+	"fmt"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+// CheckpointContents::V1 || ULEB128 count || ExecutionDigests... || user_signatures
+// ExecutionDigests: 0x20 || transaction digest || 0x20 || effects digest
+const executionDigestsLen = 2 * (1 + 32)
+
+// TransactionInclusionCircuit proves that a transaction was executed in the checkpoint whose content_digest is
+// ContentDigest. ContentDigest has the same layout as SigVerifyCircuit's Checkpoint.ContentDigest, which is how this
+// proof chains to a verified checkpoint.
+type TransactionInclusionCircuit struct {
+	// BCS encoded CheckpointContents, zero padded to the max length. The max number of transactions is derived from it.
+	CheckpointContents    []uints.U8
+	CheckpointContentsLen frontend.Variable
+	// Position of the transaction in CheckpointContents
+	TransactionIndex frontend.Variable
+
+	ContentDigest     [2]frontend.Variable `gnark:",public"`
+	TransactionDigest [2]frontend.Variable `gnark:",public"`
+	EffectsDigest     [2]frontend.Variable `gnark:",public"`
+}
+
+func (c *TransactionInclusionCircuit) Define(api frontend.API) error {
+	maxTxs := (len(c.CheckpointContents) - 3) / executionDigestsLen
+	if maxTxs < 1 {
+		return fmt.Errorf("CheckpointContents too short to hold a transaction: %d", len(c.CheckpointContents))
+	}
+
+	checkBytes(api, c.CheckpointContents)
+	contents := u8Vals(c.CheckpointContents)
+	assertZeroPadded(api, contents, c.CheckpointContentsLen)
+	digest, err := suiDigest(api, "CheckpointContents", c.CheckpointContents, c.CheckpointContentsLen)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(digest[0], c.ContentDigest[0])
+	api.AssertIsEqual(digest[1], c.ContentDigest[1])
+
+	// only V1 exists
+	api.AssertIsEqual(contents[0], 0)
+	n, wide := uleb128x2(api, contents[1], contents[2])
+
+	// TransactionIndex < n
+	sel := oneHot(api, c.TransactionIndex, maxTxs-1)
+	active := lessThanMask(api, n, maxTxs)
+	inRange := frontend.Variable(0)
+	for i := range sel {
+		inRange = api.Add(inRange, api.Mul(sel[i], active[i]))
+	}
+	api.AssertIsEqual(inRange, 1)
+
+	entry := make([]frontend.Variable, executionDigestsLen)
+	for j := range entry {
+		v := frontend.Variable(0)
+		for i, s := range sel {
+			off := 2 + i*executionDigestsLen + j
+			v = api.Add(v, api.Mul(s, api.Select(wide, byteAt(contents, off+1), contents[off])))
+		}
+		entry[j] = v
+	}
+	api.AssertIsEqual(entry[0], 32)
+	api.AssertIsEqual(entry[33], 32)
+	tx := digestLimbs(api, entry[1:33])
+	effects := digestLimbs(api, entry[34:])
+	for i := range tx {
+		api.AssertIsEqual(tx[i], c.TransactionDigest[i])
+		api.AssertIsEqual(effects[i], c.EffectsDigest[i])
+	}
+	return nil
+}
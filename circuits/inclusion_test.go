@@ -0,0 +1,64 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+	"golang.org/x/crypto/blake2b"
+	"testing"
+)
+
+func TestTransactionInclusion(t *testing.T) {
+	const maxContentsLen = 512
+	const nbTxs = 3
+	const txIndex = 1
+
+	contents, txs, effects := checkpointContentsBCS(nbTxs)
+	contentDigest := blake2b.Sum256(append([]byte("CheckpointContents::"), contents...))
+	padded := make([]byte, maxContentsLen)
+	copy(padded, contents)
+
+	c := &TransactionInclusionCircuit{CheckpointContents: make([]uints.U8, maxContentsLen)}
+	a := &TransactionInclusionCircuit{
+		CheckpointContents:    uints.NewU8Array(padded),
+		CheckpointContentsLen: len(contents),
+		TransactionIndex:      txIndex,
+		ContentDigest:         digestLimbsNative(contentDigest),
+		TransactionDigest:     digestLimbsNative(txs[txIndex]),
+		EffectsDigest:         digestLimbsNative(effects[txIndex]),
+	}
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(c, a, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+
+	// the index must point to an actual transaction, not into user_signatures or padding
+	a.TransactionIndex = nbTxs
+	assert.SolvingFailed(c, a, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
+
+// checkpointContentsBCS builds bcs(CheckpointContents::V1) with n transactions, each with one signature
+func checkpointContentsBCS(n int) (b []byte, txs, effects [][32]byte) {
+	b = append(b, 0, byte(n))
+	for i := 0; i < n; i++ {
+		var tx, fx [32]byte
+		for j := range tx {
+			tx[j] = byte(i*32 + j)
+			fx[j] = ^byte(i*32 + j)
+		}
+		txs = append(txs, tx)
+		effects = append(effects, fx)
+		b = append(b, 32)
+		b = append(b, tx[:]...)
+		b = append(b, 32)
+		b = append(b, fx[:]...)
+	}
+	b = append(b, byte(n))
+	for i := 0; i < n; i++ {
+		// a single 97-byte ed25519 GenericSignature
+		b = append(b, 1, 97)
+		for j := 0; j < 97; j++ {
+			b = append(b, byte(j))
+		}
+	}
+	return b, txs, effects
+}
@@ -0,0 +1,98 @@
+package circuits
+
+import (
+	"errors"
+	"fmt"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
+	"github.com/patrickmao1/zuika/sui"
+	"math/big"
+)
+
+var (
+	ErrBadSignerMap          = errors.New("bad signer map")
+	ErrInsufficientStake     = errors.New("insufficient stake")
+	ErrCommitteeRootMismatch = errors.New("committee root mismatch")
+	ErrBadAggregateSignature = errors.New("bad aggregate signature")
+)
+
+// Precheck natively runs the checks of SigVerifyCircuit on the inputs NewSigVerifyAssignment would assign, so that an
+// unprovable checkpoint fails fast with a typed error instead of after minutes of proving. committeeRoot is the root
+// the contract expects, nil skips the check.
+func Precheck(
+	committee *sui.Committee,
+	checkpoint *sui.CertifiedCheckpointSummary,
+	p SigVerifyParams,
+	committeeRoot *big.Int,
+) error {
+	num, den, err := p.Quorum()
+	if err != nil {
+		return err
+	}
+	in, err := newSigVerifyInputs(committee, checkpoint, p)
+	if err != nil {
+		return err
+	}
+	if committeeRoot != nil && committeeRoot.Cmp(in.root) != 0 {
+		return fmt.Errorf("%w: committee of epoch %d has root %x, expected %x",
+			ErrCommitteeRootMismatch, committee.Epoch, in.root, committeeRoot)
+	}
+
+	var signed uint64
+	var aggPubKey bls12381.G2Jac
+	for i, s := range in.signerMap {
+		if s {
+			signed += in.stakes[i]
+			aggPubKey.AddMixed(&in.pubkeys[i])
+		}
+	}
+	if !HasQuorum(signed, in.totalStake, num, den) {
+		return fmt.Errorf("%w: signed stake %d of %d", ErrInsufficientStake, signed, in.totalStake)
+	}
+
+	var pubkey bls12381.G2Affine
+	pubkey.FromJacobian(&aggPubKey)
+	if pubkey.IsInfinity() || in.sig.IsInfinity() {
+		return fmt.Errorf("%w: point at infinity", ErrBadAggregateSignature)
+	}
+	h := hashToG1FromLimbs(in.expanded0, in.expanded1)
+	_, _, _, g2 := bls12381.Generators()
+	var negG2 bls12381.G2Affine
+	negG2.Neg(&g2)
+	ok, err := bls12381.PairingCheck([]bls12381.G1Affine{in.sig, h}, []bls12381.G2Affine{negG2, pubkey})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: pairing check failed", ErrBadAggregateSignature)
+	}
+	return nil
+}
+
+// HasQuorum natively runs the quorum check of SigVerifyCircuit: signed * den > total * num. It is computed in 128 bits
+// since stakes are u64.
+func HasQuorum(signed, total uint64, num, den int) bool {
+	lhs := new(big.Int).Mul(new(big.Int).SetUint64(signed), big.NewInt(int64(den)))
+	rhs := new(big.Int).Mul(new(big.Int).SetUint64(total), big.NewInt(int64(num)))
+	return lhs.Cmp(rhs) > 0
+}
+
+// hashToG1FromLimbs finishes hash_to_curve from the expand_message_xmd output in the layout of ExpandedLimbs, the way
+// the circuit does from CheckpointSummaryExpanded0/1
+func hashToG1FromLimbs(l0, l1 [3]*big.Int) bls12381.G1Affine {
+	toField := func(l [3]*big.Int) fp.Element {
+		b := make([]byte, 64)
+		l[0].FillBytes(b[:2])
+		l[1].FillBytes(b[2:33])
+		l[2].FillBytes(b[33:])
+		var u fp.Element
+		u.SetBytes(b)
+		return u
+	}
+	// The cofactor clearing of MapToG1 is linear, so summing the two mapped points matches HashToG1
+	q0 := bls12381.MapToG1(toField(l0))
+	q1 := bls12381.MapToG1(toField(l1))
+	var h bls12381.G1Affine
+	h.Add(&q0, &q1)
+	return h
+}
@@ -0,0 +1,81 @@
+package circuits
+
+import (
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/frontend"
+	"github.com/patrickmao1/zuika/sui"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func TestPrecheck(t *testing.T) {
+	privs, pubs := genBlsKeyPairs(3)
+	committee := &sui.Committee{Epoch: 736}
+	for i, pub := range pubs {
+		committee.Members = append(committee.Members, sui.CommitteeMember{PubKey: pub.Bytes(), Stake: uint64(3000 + 1000*i)})
+	}
+	prev := sui.Digest{0xbb}
+	summary := sui.CheckpointSummary{
+		Epoch:          736,
+		SequenceNumber: 134973309,
+		ContentDigest:  sui.Digest{0xaa},
+		PreviousDigest: &prev,
+		TimestampMs:    1744911576632,
+	}
+	msgG1, err := bls12381.HashToG1(summary.SigningMessage(), testDstG1)
+	require.NoError(t, err)
+	sign := func(signerMap ...frontend.Variable) *sui.CertifiedCheckpointSummary {
+		var signers []uint32
+		for i, s := range signerMap {
+			if s == 1 {
+				signers = append(signers, uint32(i))
+			}
+		}
+		agg := aggSigs(signMulti(&msgG1, privs, signerMap))
+		return &sui.CertifiedCheckpointSummary{
+			Summary: summary,
+			AuthSignature: sui.AuthorityQuorumSignInfo{
+				Epoch:      736,
+				Signature:  agg.Bytes(),
+				SignersMap: sui.EncodeSignersMap(signers),
+			},
+		}
+	}
+	p := SigVerifyParams{MaxAuthorities: 4}
+	root, err := SuiCommitteeRoot(committee, p.MaxAuthorities)
+	require.NoError(t, err)
+
+	// Hashing from the expanded limbs matches HashToG1
+	l0, l1, err := ExpandedLimbs(summary.SigningMessage())
+	require.NoError(t, err)
+	h := hashToG1FromLimbs(l0, l1)
+	require.True(t, h.Equal(&msgG1))
+
+	require.NoError(t, Precheck(committee, sign(0, 1, 1), p, root))
+	require.NoError(t, Precheck(committee, sign(1, 1, 1), p, nil))
+	// 7000 of 12000 is not a quorum
+	require.ErrorIs(t, Precheck(committee, sign(1, 1, 0), p, root), ErrInsufficientStake)
+	// but is a 1/2 quorum
+	half := p
+	half.QuorumNumerator, half.QuorumDenominator = 1, 2
+	require.NoError(t, Precheck(committee, sign(1, 1, 0), half, root))
+	half.QuorumNumerator = 2
+	require.ErrorContains(t, Precheck(committee, sign(0, 1, 1), half, root), "invalid quorum")
+	require.ErrorIs(t, Precheck(committee, sign(0, 1, 1), p, big.NewInt(1)), ErrCommitteeRootMismatch)
+
+	// Signer map not matching the signature
+	forged := sign(0, 1, 1)
+	forged.AuthSignature.SignersMap = sui.EncodeSignersMap([]uint32{0, 1, 2})
+	require.ErrorIs(t, Precheck(committee, forged, p, root), ErrBadAggregateSignature)
+	forged.AuthSignature.SignersMap = sui.EncodeSignersMap([]uint32{1, 2, 3})
+	require.ErrorIs(t, Precheck(committee, forged, p, root), ErrBadSignerMap)
+
+	// Signature over another message
+	forged = sign(0, 1, 1)
+	forged.Summary.TimestampMs++
+	require.ErrorIs(t, Precheck(committee, forged, p, root), ErrBadAggregateSignature)
+	forged = sign(0, 1, 1)
+	forged.AuthSignature.Signature[0] ^= 0x01
+	require.ErrorIs(t, Precheck(committee, forged, p, root), ErrBadAggregateSignature)
+}
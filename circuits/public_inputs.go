@@ -0,0 +1,52 @@
+package circuits
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+)
+
+// NbPublicInputs is the number of public inputs of SigVerifyCircuit
+func NbPublicInputs(expandInCircuit bool) int {
+	if expandInCircuit {
+		return 12
+	}
+	return 18
+}
+
+// PublicInputs computes the public inputs of SigVerifyCircuit for the signed message checkpointIntent the way
+// ZKLightClient.updateCheckpoint does, given the committee root and total stake the contract currently trusts. They are
+// in the order of the public witness, which is the order the contract packs them in:
+//
+//	expand_message_xmd limbs (6, unless expandInCircuit), CommitteeRoot, TotalStake, sha256 limbs (2),
+//	epoch, sequence number, network total transactions, content digest limbs (2), previous digest limbs (2), timestamp
+func PublicInputs(checkpointIntent []byte, committeeRoot *big.Int, totalStake uint64, expandInCircuit bool) ([]*big.Int, error) {
+	msg := checkpointIntent
+	if len(msg) < offsetCheckpointCommitments {
+		return nil, fmt.Errorf("signing message length %d shorter than %d", len(msg), offsetCheckpointCommitments)
+	}
+	inputs := make([]*big.Int, 0, NbPublicInputs(expandInCircuit))
+	if !expandInCircuit {
+		l0, l1, err := ExpandedLimbs(msg)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, l0[:]...)
+		inputs = append(inputs, l1[:]...)
+	}
+	u64 := func(offset int) *big.Int {
+		return new(big.Int).SetUint64(binary.LittleEndian.Uint64(msg[offset:]))
+	}
+	digest := func(d []byte) []*big.Int {
+		return []*big.Int{new(big.Int).SetBytes(d[:16]), new(big.Int).SetBytes(d[16:32])}
+	}
+	hash := sha256.Sum256(msg)
+	inputs = append(inputs, new(big.Int).Set(committeeRoot), new(big.Int).SetUint64(totalStake))
+	inputs = append(inputs, digest(hash[:])...)
+	inputs = append(inputs, u64(offsetEpoch), u64(offsetSequenceNumber), u64(offsetNetworkTotalTransactions))
+	inputs = append(inputs, digest(msg[offsetContentDigest+1:])...)
+	inputs = append(inputs, digest(msg[offsetPreviousDigest+2:])...)
+	inputs = append(inputs, u64(offsetTimestampMs))
+	return inputs, nil
+}
@@ -0,0 +1,171 @@
+package circuits
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/frontend"
+	"github.com/patrickmao1/zuika/sui"
+	"github.com/stretchr/testify/require"
+	"math"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var updateVectors = flag.Bool("update-vectors", false, "rewrite the public input vectors shared with the contract tests")
+
+// publicInputsVectorsPath is read by contracts/test/PublicInputs.t.sol
+const publicInputsVectorsPath = "../contracts/test/vectors/public_inputs.json"
+
+// publicInputsVector is an updateCheckpoint call and the public inputs ZKLightClient packs for it. Byte strings are
+// 0x-prefixed hex and publicInputs is the abi.encodePacked uint256 words.
+type publicInputsVector struct {
+	Name             string `json:"name"`
+	CheckpointIntent string `json:"checkpointIntent"`
+	CommitteeRoot    string `json:"committeeRoot"`
+	TotalStake       uint64 `json:"totalStake,string"`
+	ExpandInContract bool   `json:"expandInContract"`
+	PublicInputs     string `json:"publicInputs"`
+}
+
+func TestPublicInputs(t *testing.T) {
+	privs, pubs := genBlsKeyPairs(3)
+	committee := &sui.Committee{Epoch: 736}
+	for i, pub := range pubs {
+		committee.Members = append(committee.Members, sui.CommitteeMember{PubKey: pub.Bytes(), Stake: uint64(3000 + 1000*i)})
+	}
+	prev := sui.Digest{0xbb}
+	summary := sui.CheckpointSummary{
+		Epoch:                    736,
+		SequenceNumber:           134973309,
+		NetworkTotalTransactions: 3407759740,
+		ContentDigest:            sui.Digest{0xaa},
+		PreviousDigest:           &prev,
+		TimestampMs:              1744911576632,
+	}
+	msg := summary.SigningMessage()
+	msgG1, err := bls12381.HashToG1(msg, testDstG1)
+	require.NoError(t, err)
+	agg := aggSigs(signMulti(&msgG1, privs, []frontend.Variable{1, 1, 0}))
+	checkpoint := &sui.CertifiedCheckpointSummary{
+		Summary: summary,
+		AuthSignature: sui.AuthorityQuorumSignInfo{
+			Epoch:      736,
+			Signature:  agg.Bytes(),
+			SignersMap: sui.EncodeSignersMap([]uint32{0, 1}),
+		},
+	}
+
+	for _, expandInCircuit := range []bool{false, true} {
+		p := SigVerifyParams{MaxAuthorities: 4, ExpandInCircuit: expandInCircuit}
+		assignment, err := NewSigVerifyAssignment(committee, checkpoint, p)
+		require.NoError(t, err)
+		w, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+		require.NoError(t, err)
+		pub, err := w.Public()
+		require.NoError(t, err)
+		vector := pub.Vector().(fr.Vector)
+
+		root, err := SuiCommitteeRoot(committee, p.MaxAuthorities)
+		require.NoError(t, err)
+		inputs, err := PublicInputs(msg, root, committee.TotalStake(), expandInCircuit)
+		require.NoError(t, err)
+		require.Len(t, inputs, NbPublicInputs(expandInCircuit))
+		require.Len(t, vector, len(inputs))
+		for i := range inputs {
+			require.Equal(t, vector[i].BigInt(new(big.Int)).String(), inputs[i].String(), "input %d", i)
+		}
+	}
+
+	_, err = PublicInputs(msg[:offsetCheckpointCommitments-1], big.NewInt(1), 1, false)
+	require.Error(t, err)
+}
+
+func TestPublicInputsVectors(t *testing.T) {
+	var vectors []publicInputsVector
+	add := func(name string, msg []byte, root *big.Int, totalStake uint64, expandInContract bool) {
+		inputs, err := PublicInputs(msg, root, totalStake, !expandInContract)
+		require.NoError(t, err)
+		var packed []byte
+		for _, v := range inputs {
+			packed = append(packed, v.FillBytes(make([]byte, 32))...)
+		}
+		vectors = append(vectors, publicInputsVector{
+			Name:             name,
+			CheckpointIntent: "0x" + hex.EncodeToString(msg),
+			CommitteeRoot:    "0x" + hex.EncodeToString(root.FillBytes(make([]byte, 32))),
+			TotalStake:       totalStake,
+			ExpandInContract: expandInContract,
+			PublicInputs:     "0x" + hex.EncodeToString(packed),
+		})
+	}
+
+	// Signing message of checkpoint 134973309 and the root of its committee at 120 authorities, as in ZKLightClient.t.sol
+	mainnet, err := hex.DecodeString("020000e0020000000000007d870b08000000007c491ecb0000000020e767c5b2706f4d810d28664f9b5b0731d085156a3afcb72ddf67373cdf99057f012067d6d26500b1403a1a464cbd64d0aa61e02eace13f2c267f970f70bd3ed4fd38000000000000000000000000000000000000000000000000000000000000000038aed544960100000000020000e002000000000000")
+	require.NoError(t, err)
+	mainnetRoot, err := SuiCommitteeRoot(readCommitteeInfo(t, "../suiclient/testdata/suix_getCommitteeInfo_736.json"), 120)
+	require.NoError(t, err)
+	add("checkpoint 134973309", mainnet, mainnetRoot, 10000, true)
+	add("checkpoint 134973309, expanded in circuit", mainnet, mainnetRoot, 10000, false)
+
+	max := sui.Digest{}
+	for i := range max {
+		max[i] = 0xff
+	}
+	summary := sui.CheckpointSummary{
+		Epoch:                    math.MaxUint64,
+		SequenceNumber:           math.MaxUint64,
+		NetworkTotalTransactions: math.MaxUint64,
+		ContentDigest:            max,
+		PreviousDigest:           &max,
+		TimestampMs:              math.MaxUint64,
+	}
+	maxRoot := new(big.Int).Sub(fr.Modulus(), big.NewInt(1))
+	add("max values", summary.SigningMessage(), maxRoot, math.MaxUint64, true)
+
+	summary = sui.CheckpointSummary{PreviousDigest: &sui.Digest{}}
+	add("zero values", summary.SigningMessage(), new(big.Int), 0, true)
+
+	b, err := json.MarshalIndent(map[string]any{"vectors": vectors}, "", "  ")
+	require.NoError(t, err)
+	b = append(b, '\n')
+	if *updateVectors {
+		require.NoError(t, os.WriteFile(publicInputsVectorsPath, b, 0644))
+	}
+	expected, err := os.ReadFile(publicInputsVectorsPath)
+	require.NoError(t, err)
+	require.Equal(t, strings.TrimSpace(string(expected)), strings.TrimSpace(string(b)),
+		"vectors are out of date, run go test ./circuits -run TestPublicInputsVectors -update-vectors")
+}
+
+// readCommitteeInfo decodes the committee of a suix_getCommitteeInfo response
+func readCommitteeInfo(t *testing.T, path string) *sui.Committee {
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var resp struct {
+		Result struct {
+			Epoch      uint64      `json:"epoch,string"`
+			Validators [][2]string `json:"validators"`
+		} `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(b, &resp))
+	committee := &sui.Committee{Epoch: resp.Result.Epoch}
+	for _, v := range resp.Result.Validators {
+		var m sui.CommitteeMember
+		pub, err := base64.StdEncoding.DecodeString(v[0])
+		require.NoError(t, err)
+		require.Len(t, pub, len(m.PubKey))
+		copy(m.PubKey[:], pub)
+		m.Stake, err = strconv.ParseUint(v[1], 10, 64)
+		require.NoError(t, err)
+		committee.Members = append(committee.Members, m)
+	}
+	return committee
+}
@@ -0,0 +1,244 @@
+package circuits
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/field/hash"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/patrickmao1/zuika/sui"
+	"math/big"
+)
+
+const DefaultMaxCheckpointSummaryLen = 256
+
+// SigVerifyParams are the compile time parameters of SigVerifyCircuit. The placeholder circuit and all assignments
+// must be built with the same parameters.
+type SigVerifyParams struct {
+	MaxAuthorities int
+	// Max length of the signed message. Zero falls back to DefaultMaxCheckpointSummaryLen.
+	MaxCheckpointSummaryLen int
+	ExpandInCircuit         bool
+	// Quorum rule of the circuit, see SigVerifyCircuit.QuorumNumerator. Zero values fall back to
+	// DefaultQuorumNumerator and DefaultQuorumDenominator.
+	QuorumNumerator   int
+	QuorumDenominator int
+	// Curve whose scalar field the circuit is compiled over, the CommitteeRoot depends on it. Zero falls back to
+	// BN254, use BLS12_377 for the inner proofs of Aggregator.
+	Curve ecc.ID
+}
+
+func (p SigVerifyParams) maxCheckpointSummaryLen() int {
+	if p.MaxCheckpointSummaryLen == 0 {
+		return DefaultMaxCheckpointSummaryLen
+	}
+	return p.MaxCheckpointSummaryLen
+}
+
+// Quorum returns the quorum rule of p with the defaults applied
+func (p SigVerifyParams) Quorum() (num, den int, err error) {
+	return quorum(p.QuorumNumerator, p.QuorumDenominator)
+}
+
+func (p SigVerifyParams) curve() ecc.ID {
+	if p.Curve == ecc.UNKNOWN {
+		return ecc.BN254
+	}
+	return p.Curve
+}
+
+// NewSigVerifyCircuit returns the placeholder circuit to compile
+func NewSigVerifyCircuit(p SigVerifyParams) *SigVerifyCircuit {
+	var inf bls12381.G2Affine
+	inf.SetInfinity()
+	c := &SigVerifyCircuit{
+		QuorumNumerator:     p.QuorumNumerator,
+		QuorumDenominator:   p.QuorumDenominator,
+		ExpandInCircuit:     p.ExpandInCircuit,
+		CommitteePubKeys:    make([]sw_bls12381.G2Affine, p.MaxAuthorities),
+		CommitteeStakeUnits: make([]frontend.Variable, p.MaxAuthorities),
+		SignerMap:           make([]frontend.Variable, p.MaxAuthorities),
+		CheckpointSummary:   make([]uints.U8, p.maxCheckpointSummaryLen()),
+	}
+	for i := range c.CommitteePubKeys {
+		c.CommitteePubKeys[i] = sw_bls12381.NewG2Affine(inf)
+	}
+	if !p.ExpandInCircuit {
+		c.CheckpointSummaryExpanded0 = make([]frontend.Variable, 3)
+		c.CheckpointSummaryExpanded1 = make([]frontend.Variable, 3)
+	}
+	return c
+}
+
+// NewSigVerifyAssignment builds the assignment of SigVerifyCircuit proving that checkpoint is certified by committee
+func NewSigVerifyAssignment(
+	committee *sui.Committee,
+	checkpoint *sui.CertifiedCheckpointSummary,
+	p SigVerifyParams,
+) (*SigVerifyCircuit, error) {
+	in, err := newSigVerifyInputs(committee, checkpoint, p)
+	if err != nil {
+		return nil, err
+	}
+	summary := &checkpoint.Summary
+	padded := make([]byte, p.maxCheckpointSummaryLen())
+	copy(padded, in.msg)
+
+	a := &SigVerifyCircuit{
+		QuorumNumerator:       p.QuorumNumerator,
+		QuorumDenominator:     p.QuorumDenominator,
+		ExpandInCircuit:       p.ExpandInCircuit,
+		CommitteePubKeys:      make([]sw_bls12381.G2Affine, p.MaxAuthorities),
+		CommitteeStakeUnits:   make([]frontend.Variable, p.MaxAuthorities),
+		SignerMap:             make([]frontend.Variable, p.MaxAuthorities),
+		AggSig:                sw_bls12381.NewG1Affine(in.sig),
+		CheckpointSummary:     uints.NewU8Array(padded),
+		CheckpointSummaryLen:  len(in.msg),
+		CommitteeRoot:         in.root,
+		TotalStake:            in.totalStake,
+		CheckpointSummaryHash: bytesToLimbs(sha256Sum(in.msg)),
+		Checkpoint: CheckpointSummaryFields{
+			Epoch:                    summary.Epoch,
+			SequenceNumber:           summary.SequenceNumber,
+			NetworkTotalTransactions: summary.NetworkTotalTransactions,
+			ContentDigest:            bytesToLimbs(summary.ContentDigest[:]),
+			PreviousDigest:           bytesToLimbs(summary.PreviousDigest[:]),
+			TimestampMs:              summary.TimestampMs,
+		},
+	}
+	for i := range in.pubkeys {
+		a.CommitteePubKeys[i] = sw_bls12381.NewG2Affine(in.pubkeys[i])
+		a.CommitteeStakeUnits[i] = in.stakes[i]
+		a.SignerMap[i] = 0
+		if in.signerMap[i] {
+			a.SignerMap[i] = 1
+		}
+	}
+	if !p.ExpandInCircuit {
+		a.CheckpointSummaryExpanded0 = []frontend.Variable{in.expanded0[0], in.expanded0[1], in.expanded0[2]}
+		a.CheckpointSummaryExpanded1 = []frontend.Variable{in.expanded1[0], in.expanded1[1], in.expanded1[2]}
+	}
+	return a, nil
+}
+
+// sigVerifyInputs are the native values NewSigVerifyAssignment assigns and Precheck checks
+type sigVerifyInputs struct {
+	// Padded to MaxAuthorities
+	pubkeys    []bls12381.G2Affine
+	stakes     []uint64
+	signerMap  []bool
+	sig        bls12381.G1Affine
+	msg        []byte
+	root       *big.Int
+	totalStake uint64
+	// Computed even if the circuit expands the message itself
+	expanded0, expanded1 [3]*big.Int
+}
+
+func newSigVerifyInputs(
+	committee *sui.Committee,
+	checkpoint *sui.CertifiedCheckpointSummary,
+	p SigVerifyParams,
+) (*sigVerifyInputs, error) {
+	summary := &checkpoint.Summary
+	if summary.Epoch != committee.Epoch || checkpoint.AuthSignature.Epoch != committee.Epoch {
+		return nil, fmt.Errorf("checkpoint of epoch %d signed in epoch %d, committee of epoch %d",
+			summary.Epoch, checkpoint.AuthSignature.Epoch, committee.Epoch)
+	}
+	if len(committee.Members) > p.MaxAuthorities {
+		return nil, fmt.Errorf("committee size %d exceeds max authorities %d", len(committee.Members), p.MaxAuthorities)
+	}
+	if summary.PreviousDigest == nil {
+		return nil, fmt.Errorf("checkpoint %d has no previous digest", summary.SequenceNumber)
+	}
+
+	pubkeys, stakes, err := PadCommittee(committee, p.MaxAuthorities)
+	if err != nil {
+		return nil, err
+	}
+	root, err := CommitteeRootFor(p.curve(), pubkeys, stakes)
+	if err != nil {
+		return nil, err
+	}
+	signerMap, err := sui.DecodeSignerBits(checkpoint.AuthSignature.SignersMap, len(committee.Members), p.MaxAuthorities)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBadSignerMap, err)
+	}
+	sig, err := checkpoint.AuthSignature.G1()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBadAggregateSignature, err)
+	}
+
+	msg := summary.SigningMessage()
+	if len(msg) > p.maxCheckpointSummaryLen() {
+		return nil, fmt.Errorf("signing message length %d exceeds max %d", len(msg), p.maxCheckpointSummaryLen())
+	}
+	l0, l1, err := ExpandedLimbs(msg)
+	if err != nil {
+		return nil, err
+	}
+	return &sigVerifyInputs{
+		pubkeys:    pubkeys,
+		stakes:     stakes,
+		signerMap:  signerMap,
+		sig:        sig,
+		msg:        msg,
+		root:       root,
+		totalStake: committee.TotalStake(),
+		expanded0:  l0,
+		expanded1:  l1,
+	}, nil
+}
+
+// PadCommittee decompresses the committee's public keys and pads keys and stakes to maxAuthorities with infinity and
+// zero stake, the layout of CommitteePubKeys and CommitteeStakeUnits
+func PadCommittee(committee *sui.Committee, maxAuthorities int) ([]bls12381.G2Affine, []uint64, error) {
+	if len(committee.Members) > maxAuthorities {
+		return nil, nil, fmt.Errorf("committee size %d exceeds max authorities %d", len(committee.Members), maxAuthorities)
+	}
+	pubkeys, err := committee.PubKeys()
+	if err != nil {
+		return nil, nil, err
+	}
+	padded := make([]bls12381.G2Affine, maxAuthorities)
+	stakes := make([]uint64, maxAuthorities)
+	for i := range padded {
+		if i < len(pubkeys) {
+			padded[i] = pubkeys[i]
+			stakes[i] = committee.Members[i].Stake
+		} else {
+			padded[i].SetInfinity()
+		}
+	}
+	return padded, stakes, nil
+}
+
+// ExpandedLimbs computes expand_message_xmd(msg) and splits each 64-byte half into big-endian limbs of 2, 31 and 31
+// bytes, the layout of CheckpointSummaryExpanded0/1
+func ExpandedLimbs(msg []byte) (l0, l1 [3]*big.Int, err error) {
+	xmd, err := hash.ExpandMsgXmd(msg, blsSigDst, 128)
+	if err != nil {
+		return l0, l1, err
+	}
+	split := func(half []byte) [3]*big.Int {
+		return [3]*big.Int{
+			new(big.Int).SetBytes(half[:2]),
+			new(big.Int).SetBytes(half[2:33]),
+			new(big.Int).SetBytes(half[33:64]),
+		}
+	}
+	return split(xmd[:64]), split(xmd[64:]), nil
+}
+
+func sha256Sum(b []byte) []byte {
+	h := sha256.Sum256(b)
+	return h[:]
+}
+
+// bytesToLimbs is the native counterpart of digestLimbs
+func bytesToLimbs(digest []byte) [2]frontend.Variable {
+	return [2]frontend.Variable{new(big.Int).SetBytes(digest[:16]), new(big.Int).SetBytes(digest[16:32])}
+}
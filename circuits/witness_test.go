@@ -0,0 +1,65 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/patrickmao1/zuika/sui"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNewSigVerifyAssignmentCurve(t *testing.T) {
+	_, pubs := genBlsKeyPairs(2)
+	committee := &sui.Committee{Epoch: 736}
+	for _, pub := range pubs {
+		committee.Members = append(committee.Members, sui.CommitteeMember{PubKey: pub.Bytes(), Stake: 5000})
+	}
+	_, _, g1, _ := bls12381.Generators()
+	checkpoint := &sui.CertifiedCheckpointSummary{
+		Summary: sui.CheckpointSummary{Epoch: 736, SequenceNumber: 1, PreviousDigest: &sui.Digest{}},
+		AuthSignature: sui.AuthorityQuorumSignInfo{
+			Epoch:      736,
+			Signature:  g1.Bytes(),
+			SignersMap: sui.EncodeSignersMap([]uint32{0, 1}),
+		},
+	}
+	pubkeys, stakes, err := PadCommittee(committee, 4)
+	require.NoError(t, err)
+
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_377} {
+		a, err := NewSigVerifyAssignment(committee, checkpoint, SigVerifyParams{MaxAuthorities: 4, Curve: curve})
+		require.NoError(t, err)
+		root, err := CommitteeRootFor(curve, pubkeys, stakes)
+		require.NoError(t, err)
+		require.Equal(t, root, a.CommitteeRoot, curve.String())
+	}
+	// BN254 by default
+	a, err := NewSigVerifyAssignment(committee, checkpoint, SigVerifyParams{MaxAuthorities: 4})
+	require.NoError(t, err)
+	root, err := CommitteeRoot(pubkeys, stakes)
+	require.NoError(t, err)
+	require.Equal(t, root, a.CommitteeRoot)
+
+	_, err = NewSigVerifyAssignment(committee, checkpoint, SigVerifyParams{MaxAuthorities: 4, Curve: ecc.BW6_761})
+	require.Error(t, err)
+}
+
+func TestSigVerifyParamsQuorum(t *testing.T) {
+	num, den, err := SigVerifyParams{}.Quorum()
+	require.NoError(t, err)
+	require.Equal(t, []int{DefaultQuorumNumerator, DefaultQuorumDenominator}, []int{num, den})
+	num, den, err = SigVerifyParams{QuorumNumerator: 1, QuorumDenominator: 2}.Quorum()
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2}, []int{num, den})
+
+	// An invalid quorum fails compilation instead of panicking in Define
+	for _, q := range [][2]int{{3, 2}, {2, 2}, {0, 3}, {1, 0}} {
+		p := SigVerifyParams{MaxAuthorities: 1, QuorumNumerator: q[0], QuorumDenominator: q[1]}
+		_, _, err := p.Quorum()
+		require.Error(t, err)
+		_, err = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, NewSigVerifyCircuit(p))
+		require.ErrorContains(t, err, "invalid quorum")
+	}
+}
@@ -0,0 +1,103 @@
+package circuits
+
+import (
+	"fmt"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/sha2"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+// Domain separation tag Sui uses for BLS signatures on G1
+var blsSigDst = []byte("BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_NUL_")
+
+// HashToG1 hashes msg to G1 the way Sui does before signing it
+func HashToG1(msg []byte) (bls12381.G1Affine, error) {
+	return bls12381.HashToG1(msg, blsSigDst)
+}
+
+// expandMsgXmd implements RFC 9380 expand_message_xmd with SHA-256 over the first length bytes of msg. msg is the
+// max-length buffer, bytes from length onwards are ignored.
+func expandMsgXmd(api frontend.API, msg []uints.U8, length frontend.Variable, dst []byte, lenInBytes int) ([]uints.U8, error) {
+	const bInBytes = 32
+	const rInBytes = 64
+	ell := (lenInBytes + bInBytes - 1) / bInBytes
+	if ell > 255 || lenInBytes > 65535 || len(dst) > 255 {
+		return nil, fmt.Errorf("invalid expand_message_xmd parameters: len_in_bytes %d, len(dst) %d", lenInBytes, len(dst))
+	}
+	bf, err := uints.New[uints.U32](api)
+	if err != nil {
+		return nil, err
+	}
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+
+	// msg_prime = Z_pad || msg || I2OSP(len_in_bytes, 2) || I2OSP(0, 1) || DST_prime
+	// The suffix after msg starts right after its last byte, hence at a position that depends on length.
+	suffix := append([]byte{byte(lenInBytes >> 8), byte(lenInBytes), 0}, dstPrime...)
+	pos := oneHot(api, length, len(msg))
+	msgPrime := make([]uints.U8, 0, rInBytes+len(msg)+len(suffix))
+	for i := 0; i < rInBytes; i++ {
+		msgPrime = append(msgPrime, uints.NewU8(0))
+	}
+	inMsg := frontend.Variable(1)
+	for j := 0; j < len(msg)+len(suffix); j++ {
+		v := frontend.Variable(0)
+		if j < len(msg) {
+			inMsg = api.Sub(inMsg, pos[j])
+			v = api.Mul(inMsg, msg[j].Val)
+		}
+		for k, s := range suffix {
+			if t := j - k; s != 0 && t >= 0 && t <= len(msg) {
+				v = api.Add(v, api.Mul(pos[t], s))
+			}
+		}
+		msgPrime = append(msgPrime, uints.U8{Val: v})
+	}
+
+	h, err := sha2.New(api)
+	if err != nil {
+		return nil, err
+	}
+	h.Write(msgPrime)
+	b0 := h.FixedLengthSum(api.Add(length, rInBytes+len(suffix)))
+
+	out := make([]uints.U8, 0, ell*bInBytes)
+	prev := b0
+	for i := 1; i <= ell; i++ {
+		// b_1 = H(b_0 || I2OSP(1, 1) || DST_prime)
+		// b_i = H(strxor(b_0, b_(i-1)) || I2OSP(i, 1) || DST_prime)
+		in := b0
+		if i > 1 {
+			in = xorBytes(bf, b0, prev)
+		}
+		h, err := sha2.New(api)
+		if err != nil {
+			return nil, err
+		}
+		h.Write(in)
+		h.Write([]uints.U8{uints.NewU8(uint8(i))})
+		h.Write(uints.NewU8Array(dstPrime))
+		prev = h.Sum()
+		out = append(out, prev...)
+	}
+	return out[:lenInBytes], nil
+}
+
+func xorBytes(bf *uints.BinaryField[uints.U32], a, b []uints.U8) []uints.U8 {
+	if len(a) != len(b) || len(a)%4 != 0 {
+		panic("xorBytes: invalid lengths")
+	}
+	res := make([]uints.U8, 0, len(a))
+	for i := 0; i < len(a); i += 4 {
+		x := bf.Xor(bf.PackMSB(a[i:i+4]...), bf.PackMSB(b[i:i+4]...))
+		res = append(res, bf.UnpackMSB(x)...)
+	}
+	return res
+}
+
+// xmdToU248Limbs splits a 64-byte half of the expand_message_xmd output into big-endian limbs of 2, 31 and 31 bytes,
+// the layout of CheckpointSummaryExpanded0/1
+func xmdToU248Limbs(api frontend.API, half []uints.U8) [3]frontend.Variable {
+	vals := u8Vals(half)
+	return [3]frontend.Variable{beUint(api, vals[:2]), beUint(api, vals[2:33]), beUint(api, vals[33:64])}
+}
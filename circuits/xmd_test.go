@@ -0,0 +1,55 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/field/hash"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type xmdCircuit struct {
+	Msg      []uints.U8
+	MsgLen   frontend.Variable
+	Expected []uints.U8
+}
+
+func (c *xmdCircuit) Define(api frontend.API) error {
+	out, err := expandMsgXmd(api, c.Msg, c.MsgLen, blsSigDst, len(c.Expected))
+	if err != nil {
+		return err
+	}
+	for i := range out {
+		api.AssertIsEqual(out[i].Val, c.Expected[i].Val)
+	}
+	return nil
+}
+
+func TestExpandMsgXmd(t *testing.T) {
+	const maxMsgLen = 200
+	for _, msgLen := range []int{0, 1, 55, 147, maxMsgLen} {
+		msg := make([]byte, msgLen)
+		for i := range msg {
+			msg[i] = byte(i*7 + 3)
+		}
+		xmd, err := hash.ExpandMsgXmd(msg, blsSigDst, 128)
+		require.NoError(t, err)
+		padded := make([]byte, maxMsgLen)
+		copy(padded, msg)
+
+		c := &xmdCircuit{
+			Msg:      make([]uints.U8, maxMsgLen),
+			Expected: make([]uints.U8, len(xmd)),
+		}
+		a := &xmdCircuit{
+			Msg:      uints.NewU8Array(padded),
+			MsgLen:   msgLen,
+			Expected: uints.NewU8Array(xmd),
+		}
+		assert := test.NewAssert(t)
+		assert.SolvingSucceeded(c, a, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/groth16/bn254/mpcsetup"
+	cs "github.com/consensys/gnark/constraint/bn254"
+	"github.com/patrickmao1/zuika/artifacts"
+	"github.com/patrickmao1/zuika/ceremony"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func runCeremonyImportPtau(args []string) error {
+	fs := newFlagSet("ceremony-import-ptau")
+	ptauPath := fs.String("ptau", "", "path of the BN254 Powers-of-Tau (.ptau) transcript")
+	ccsPath := fs.String("ccs", "", "path of the constraint system the SRS is for")
+	out := fs.String("out", "", "output path of the phase-1 SRS")
+	if err := parseFlags(fs, args, "ptau", "ccs", "out"); err != nil {
+		return err
+	}
+	r1cs, err := readR1CS(*ccsPath)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(*ptauPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	commons, header, err := ceremony.ReadPtau(f, r1cs.GetNbConstraints())
+	if err != nil {
+		return fmt.Errorf("%s: %w", *ptauPath, err)
+	}
+	fmt.Printf("ptau holds 2^%d powers, using %d for %d constraints\n",
+		header.Power, len(commons.G1.AlphaTau), r1cs.GetNbConstraints())
+	return writeArtifact(*out, commons)
+}
+
+func runCeremonyInit(args []string) error {
+	fs := newFlagSet("ceremony-init")
+	ccsPath := fs.String("ccs", "", "path of the constraint system")
+	phase1Path := fs.String("phase1", "", "path of the phase-1 SRS, e.g. from ceremony-import-ptau")
+	dir := fs.String("dir", "", "transcript directory to create")
+	manifestPath := fs.String("manifest", "", "path of the artifact manifest to check the ccs against")
+	if err := parseFlags(fs, args, "ccs", "phase1", "dir", "manifest"); err != nil {
+		return err
+	}
+	if _, err := loadManifest(*manifestPath, nil, map[string]string{artifacts.CCS: *ccsPath}); err != nil {
+		return err
+	}
+	r1cs, err := readR1CS(*ccsPath)
+	if err != nil {
+		return err
+	}
+	commons := new(mpcsetup.SrsCommons)
+	if err := readArtifact(*phase1Path, commons); err != nil {
+		return err
+	}
+	if _, err := ceremony.Init(*dir, r1cs, commons); err != nil {
+		return err
+	}
+	fmt.Println("initialized phase 2 in", *dir)
+	return nil
+}
+
+func runCeremonyContribute(args []string) error {
+	fs := newFlagSet("ceremony-contribute")
+	dir := fs.String("dir", "", "transcript directory")
+	name := fs.String("name", "", "name of the participant")
+	entropyPath := fs.String("entropy-file", "", "path of a file whose content is mixed into the system randomness")
+	if err := parseFlags(fs, args, "dir", "name"); err != nil {
+		return err
+	}
+	var entropy []byte
+	if *entropyPath != "" {
+		var err error
+		if entropy, err = os.ReadFile(*entropyPath); err != nil {
+			return err
+		}
+	}
+	t, err := ceremony.Open(*dir)
+	if err != nil {
+		return err
+	}
+	c, err := t.Contribute(*name, entropy)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("contribution %d of %s written to %s\n", c.Index, c.Participant, c.File.Path)
+	fmt.Println("sha256", c.File.SHA256)
+	return nil
+}
+
+func runCeremonyVerify(args []string) error {
+	fs := newFlagSet("ceremony-verify")
+	dir := fs.String("dir", "", "transcript directory")
+	ccsPath := fs.String("ccs", "", "path of the constraint system")
+	reportPath := fs.String("report", "", "output path of the verification report, printed if not set")
+	if err := parseFlags(fs, args, "dir", "ccs"); err != nil {
+		return err
+	}
+	t, err := ceremony.Open(*dir)
+	if err != nil {
+		return err
+	}
+	r1cs, err := readR1CS(*ccsPath)
+	if err != nil {
+		return err
+	}
+	r, verifyErr := t.Verify(r1cs)
+	if err := writeReport(*reportPath, r); err != nil {
+		return err
+	}
+	return verifyErr
+}
+
+func runCeremonySeal(args []string) error {
+	fs := newFlagSet("ceremony-seal")
+	dir := fs.String("dir", "", "transcript directory")
+	ccsPath := fs.String("ccs", "", "path of the constraint system")
+	beacon := fs.String("beacon", "", "hex encoded public random beacon")
+	pkPath := fs.String("pk", "", "output path of the proving key")
+	vkPath := fs.String("vk", "", "output path of the verifying key")
+	manifestPath := fs.String("manifest", "", "path of the artifact manifest written by compile, updated in place")
+	if err := parseFlags(fs, args, "dir", "ccs", "beacon", "pk", "vk", "manifest"); err != nil {
+		return err
+	}
+	b, err := hex.DecodeString(strings.TrimPrefix(*beacon, "0x"))
+	if err != nil {
+		return fmt.Errorf("beacon: %w", err)
+	}
+	m, err := loadManifest(*manifestPath, nil, map[string]string{artifacts.CCS: *ccsPath})
+	if err != nil {
+		return err
+	}
+	t, err := ceremony.Open(*dir)
+	if err != nil {
+		return err
+	}
+	r1cs, err := readR1CS(*ccsPath)
+	if err != nil {
+		return err
+	}
+	pk, vk, r, err := t.Seal(r1cs, b)
+	if r != nil {
+		if err := writeReport(filepath.Join(*dir, "report.json"), r); err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if err := writeArtifact(*pkPath, pk); err != nil {
+		return err
+	}
+	if err := writeArtifact(*vkPath, vk); err != nil {
+		return err
+	}
+	if err := m.Add(*manifestPath, artifacts.PK, *pkPath); err != nil {
+		return err
+	}
+	if err := m.Add(*manifestPath, artifacts.VK, *vkPath); err != nil {
+		return err
+	}
+	m.SolidityFingerprint, _, err = artifacts.SolidityFingerprint(vk)
+	if err != nil {
+		return err
+	}
+	return m.WriteFile(*manifestPath)
+}
+
+func readR1CS(path string) (*cs.R1CS, error) {
+	ccs := groth16.NewCS(ecc.BN254)
+	if err := readArtifact(path, ccs); err != nil {
+		return nil, err
+	}
+	r1cs, ok := ccs.(*cs.R1CS)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a BN254 R1CS", path)
+	}
+	return r1cs, nil
+}
+
+func writeReport(path string, r *ceremony.Report) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	if path == "" {
+		_, err := os.Stdout.Write(b)
+		return err
+	}
+	if err := os.WriteFile(path, b, 0644); err != nil {
+		return err
+	}
+	fmt.Println("wrote", path)
+	return nil
+}
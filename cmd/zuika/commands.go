@@ -0,0 +1,344 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/solidity"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/patrickmao1/zuika/artifacts"
+	"github.com/patrickmao1/zuika/circuits"
+	"github.com/patrickmao1/zuika/sui"
+	"github.com/patrickmao1/zuika/utils"
+	"math/big"
+	"os"
+	"strings"
+)
+
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet("zuika "+name, flag.ContinueOnError)
+}
+
+func paramsFlags(fs *flag.FlagSet) *circuits.SigVerifyParams {
+	p := &circuits.SigVerifyParams{}
+	fs.IntVar(&p.MaxAuthorities, "max-authorities", 120, "max committee size")
+	fs.IntVar(&p.MaxCheckpointSummaryLen, "max-summary-len", circuits.DefaultMaxCheckpointSummaryLen,
+		"max length of the signed checkpoint message")
+	fs.BoolVar(&p.ExpandInCircuit, "expand-in-circuit", false, "compute expand_message_xmd in circuit")
+	fs.IntVar(&p.QuorumNumerator, "quorum-numerator", circuits.DefaultQuorumNumerator,
+		"signed stake must exceed quorum-numerator/quorum-denominator of the total stake")
+	fs.IntVar(&p.QuorumDenominator, "quorum-denominator", circuits.DefaultQuorumDenominator,
+		"see quorum-numerator")
+	return p
+}
+
+func runCompile(args []string) error {
+	fs := newFlagSet("compile")
+	p := paramsFlags(fs)
+	out := fs.String("ccs", "", "output path of the constraint system")
+	manifestPath := fs.String("manifest", "", "output path of the artifact manifest")
+	if err := parseFlags(fs, args, "ccs", "manifest"); err != nil {
+		return err
+	}
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuits.NewSigVerifyCircuit(*p))
+	if err != nil {
+		return err
+	}
+	fmt.Println("constraints", ccs.GetNbConstraints())
+	if err := writeArtifact(*out, ccs); err != nil {
+		return err
+	}
+	m := artifacts.NewManifest(artifacts.SigVerifyParams(*p), ccs.GetNbConstraints())
+	if err := m.Add(*manifestPath, artifacts.CCS, *out); err != nil {
+		return err
+	}
+	return m.WriteFile(*manifestPath)
+}
+
+func runSetup(args []string) error {
+	fs := newFlagSet("setup")
+	ccsPath := fs.String("ccs", "", "path of the constraint system")
+	pkPath := fs.String("pk", "", "output path of the proving key")
+	vkPath := fs.String("vk", "", "output path of the verifying key")
+	manifestPath := fs.String("manifest", "", "path of the artifact manifest written by compile, updated in place")
+	if err := parseFlags(fs, args, "ccs", "pk", "vk", "manifest"); err != nil {
+		return err
+	}
+	m, err := loadManifest(*manifestPath, nil, map[string]string{artifacts.CCS: *ccsPath})
+	if err != nil {
+		return err
+	}
+	ccs := groth16.NewCS(ecc.BN254)
+	if err := readArtifact(*ccsPath, ccs); err != nil {
+		return err
+	}
+	pk, vk, err := groth16.Setup(ccs)
+	if err != nil {
+		return err
+	}
+	if err := writeArtifact(*pkPath, pk); err != nil {
+		return err
+	}
+	if err := writeArtifact(*vkPath, vk); err != nil {
+		return err
+	}
+	if err := m.Add(*manifestPath, artifacts.PK, *pkPath); err != nil {
+		return err
+	}
+	if err := m.Add(*manifestPath, artifacts.VK, *vkPath); err != nil {
+		return err
+	}
+	m.SolidityFingerprint, _, err = artifacts.SolidityFingerprint(vk)
+	if err != nil {
+		return err
+	}
+	return m.WriteFile(*manifestPath)
+}
+
+func runProve(args []string) error {
+	fs := newFlagSet("prove")
+	p := paramsFlags(fs)
+	ccsPath := fs.String("ccs", "", "path of the constraint system")
+	pkPath := fs.String("pk", "", "path of the proving key")
+	committeePath := fs.String("committee", "", "path of the committee JSON")
+	checkpointPath := fs.String("checkpoint", "", "path of the BCS encoded CertifiedCheckpointSummary")
+	committeeRootHex := fs.String("committee-root", "", "hex encoded CommitteeRoot the proof is going to be verified against")
+	proofPath := fs.String("proof", "", "output path of the proof")
+	publicPath := fs.String("public", "", "output path of the public witness")
+	manifestPath := fs.String("manifest", "", "path of the artifact manifest to check the artifacts against")
+	if err := parseFlags(fs, args, "ccs", "pk", "committee", "checkpoint", "committee-root", "proof", "public",
+		"manifest"); err != nil {
+		return err
+	}
+	committeeRoot, ok := new(big.Int).SetString(strings.TrimPrefix(*committeeRootHex, "0x"), 16)
+	if !ok {
+		return fmt.Errorf("invalid committee root %q", *committeeRootHex)
+	}
+	params := artifacts.SigVerifyParams(*p)
+	files := map[string]string{artifacts.CCS: *ccsPath, artifacts.PK: *pkPath}
+	if _, err := loadManifest(*manifestPath, &params, files); err != nil {
+		return err
+	}
+
+	committee, err := readCommittee(*committeePath)
+	if err != nil {
+		return err
+	}
+	checkpoint, err := readCertifiedCheckpoint(*checkpointPath)
+	if err != nil {
+		return err
+	}
+	if err := circuits.Precheck(committee, checkpoint, *p, committeeRoot); err != nil {
+		return err
+	}
+	assignment, err := circuits.NewSigVerifyAssignment(committee, checkpoint, *p)
+	if err != nil {
+		return err
+	}
+	w, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	if err != nil {
+		return err
+	}
+	pub, err := w.Public()
+	if err != nil {
+		return err
+	}
+
+	ccs := groth16.NewCS(ecc.BN254)
+	if err := readArtifact(*ccsPath, ccs); err != nil {
+		return err
+	}
+	pk := groth16.NewProvingKey(ecc.BN254)
+	if err := readArtifact(*pkPath, pk); err != nil {
+		return err
+	}
+	proof, err := groth16.Prove(ccs, pk, w, solidity.WithProverTargetSolidityVerifier(backend.GROTH16))
+	if err != nil {
+		return err
+	}
+	if err := writeArtifact(*proofPath, proof); err != nil {
+		return err
+	}
+	return writeArtifact(*publicPath, pub)
+}
+
+func runVerify(args []string) error {
+	fs := newFlagSet("verify")
+	vkPath := fs.String("vk", "", "path of the verifying key")
+	proofPath := fs.String("proof", "", "path of the proof")
+	publicPath := fs.String("public", "", "path of the public witness")
+	manifestPath := fs.String("manifest", "", "path of the artifact manifest to check the vk against")
+	if err := parseFlags(fs, args, "vk", "proof", "public", "manifest"); err != nil {
+		return err
+	}
+	if _, err := loadManifest(*manifestPath, nil, map[string]string{artifacts.VK: *vkPath}); err != nil {
+		return err
+	}
+	vk := groth16.NewVerifyingKey(ecc.BN254)
+	if err := readArtifact(*vkPath, vk); err != nil {
+		return err
+	}
+	proof := groth16.NewProof(ecc.BN254)
+	if err := readArtifact(*proofPath, proof); err != nil {
+		return err
+	}
+	pub, err := readPublicWitness(*publicPath)
+	if err != nil {
+		return err
+	}
+	if err := groth16.Verify(proof, vk, pub, solidity.WithVerifierTargetSolidityVerifier(backend.GROTH16)); err != nil {
+		return fmt.Errorf("invalid proof: %w", err)
+	}
+	fmt.Println("proof is valid")
+	return nil
+}
+
+func runExportSolidity(args []string) error {
+	fs := newFlagSet("export-solidity")
+	vkPath := fs.String("vk", "", "path of the verifying key")
+	out := fs.String("out", "", "output path of the Solidity verifier")
+	manifestPath := fs.String("manifest", "", "path of the artifact manifest to check the vk against")
+	if err := parseFlags(fs, args, "vk", "out", "manifest"); err != nil {
+		return err
+	}
+	m, err := loadManifest(*manifestPath, nil, map[string]string{artifacts.VK: *vkPath})
+	if err != nil {
+		return err
+	}
+	vk := groth16.NewVerifyingKey(ecc.BN254)
+	if err := readArtifact(*vkPath, vk); err != nil {
+		return err
+	}
+	_, source, err := artifacts.SolidityFingerprint(vk)
+	if err != nil {
+		return err
+	}
+	if err := m.CheckSolidity(source); err != nil {
+		return err
+	}
+	return os.WriteFile(*out, source, 0644)
+}
+
+func runInspect(args []string) error {
+	fs := newFlagSet("inspect")
+	ccsPath := fs.String("ccs", "", "path of a constraint system")
+	vkPath := fs.String("vk", "", "path of a verifying key")
+	proofPath := fs.String("proof", "", "path of a proof")
+	publicPath := fs.String("public", "", "path of a public witness")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *ccsPath == "" && *vkPath == "" && *proofPath == "" && *publicPath == "" {
+		fmt.Fprintln(fs.Output(), "at least one artifact is required")
+		fs.Usage()
+		return errUsage
+	}
+
+	info := map[string]any{}
+	if *ccsPath != "" {
+		ccs := groth16.NewCS(ecc.BN254)
+		if err := readArtifact(*ccsPath, ccs); err != nil {
+			return err
+		}
+		info["ccs"] = map[string]int{
+			"constraints":    ccs.GetNbConstraints(),
+			"publicInputs":   ccs.GetNbPublicVariables() - 1,
+			"secretInputs":   ccs.GetNbSecretVariables(),
+			"internalValues": ccs.GetNbInternalVariables(),
+		}
+	}
+	if *vkPath != "" {
+		vk := groth16.NewVerifyingKey(ecc.BN254)
+		if err := readArtifact(*vkPath, vk); err != nil {
+			return err
+		}
+		info["vk"] = map[string]int{"publicInputs": vk.NbPublicWitness()}
+	}
+	if *proofPath != "" {
+		proof := groth16.NewProof(ecc.BN254)
+		if err := readArtifact(*proofPath, proof); err != nil {
+			return err
+		}
+		p, commitments, commitmentPoK := utils.ExportProofForSolidity(proof)
+		info["proof"] = map[string]any{"proof": p, "commitments": commitments, "commitmentPok": commitmentPoK}
+	}
+	if *publicPath != "" {
+		pub, err := readPublicWitness(*publicPath)
+		if err != nil {
+			return err
+		}
+		info["public"] = pub.Vector()
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(info)
+}
+
+// loadManifest reads the manifest at path and checks it against params, if not nil, and files keyed by artifact name
+func loadManifest(path string, params *artifacts.CircuitParams, files map[string]string) (*artifacts.Manifest, error) {
+	if path == "" {
+		return nil, errors.New("no artifact manifest to check the artifacts against")
+	}
+	m, err := artifacts.ReadManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	expected := m.Params
+	if params != nil {
+		expected = *params
+	}
+	if err := m.Check(expected); err != nil {
+		return nil, err
+	}
+	for name, file := range files {
+		if err := m.CheckFile(name, file); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func readPublicWitness(path string) (witness.Witness, error) {
+	pub, err := witness.New(ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := pub.UnmarshalBinary(b); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return pub, nil
+}
+
+func readCommittee(path string) (*sui.Committee, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	committee := new(sui.Committee)
+	if err := json.Unmarshal(b, committee); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return committee, nil
+}
+
+func readCertifiedCheckpoint(path string) (*sui.CertifiedCheckpointSummary, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	checkpoint := new(sui.CertifiedCheckpointSummary)
+	if err := checkpoint.UnmarshalBCS(b); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return checkpoint, nil
+}
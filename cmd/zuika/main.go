@@ -0,0 +1,103 @@
+// Command zuika compiles SigVerifyCircuit, runs its setup and proves Sui checkpoints for ZKLightClient.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+type command struct {
+	name  string
+	short string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{"compile", "compile SigVerifyCircuit into a constraint system", runCompile},
+	{"setup", "run the (single-party, testing only) Groth16 setup of a constraint system", runSetup},
+	{"ceremony-import-ptau", "convert a Powers-of-Tau transcript into the phase-1 SRS of a constraint system", runCeremonyImportPtau},
+	{"ceremony-init", "start the phase-2 MPC setup of a constraint system from a phase-1 SRS", runCeremonyInit},
+	{"ceremony-contribute", "add a contribution to a phase-2 transcript", runCeremonyContribute},
+	{"ceremony-verify", "verify all contributions of a phase-2 transcript", runCeremonyVerify},
+	{"ceremony-seal", "verify a phase-2 transcript and derive the proving and verifying keys", runCeremonySeal},
+	{"prove", "prove a certified checkpoint against its committee", runProve},
+	{"serve", "run the prover service holding the ccs and pk in memory", runServe},
+	{"relay", "prove the latest Sui checkpoints and submit them to ZKLightClient", runRelay},
+	{"verify", "verify a proof against its public witness", runVerify},
+	{"export-solidity", "export the Solidity verifier of a verifying key", runExportSolidity},
+	{"inspect", "print information about artifacts", runInspect},
+}
+
+// errUsage is returned by commands on invalid arguments, the flag set has already printed the usage
+var errUsage = errors.New("usage")
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		usage()
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+		err := cmd.run(args[1:])
+		switch {
+		case err == nil:
+			return exitOK
+		case errors.Is(err, errUsage), errors.Is(err, flag.ErrHelp):
+			return exitUsage
+		default:
+			fmt.Fprintf(os.Stderr, "zuika %s: %v\n", cmd.name, err)
+			return exitFailure
+		}
+	}
+	fmt.Fprintf(os.Stderr, "zuika: unknown command %q\n", args[0])
+	usage()
+	return exitUsage
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: zuika <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-20s %s\n", cmd.name, cmd.short)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "run zuika <command> -h for the flags of a command")
+}
+
+// parseFlags parses args and checks that all required flags are set
+func parseFlags(fs *flag.FlagSet, args []string, required ...string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(fs.Output(), "unexpected arguments: %v\n", fs.Args())
+		fs.Usage()
+		return errUsage
+	}
+	for _, name := range required {
+		if fs.Lookup(name).Value.String() == "" {
+			fmt.Fprintf(fs.Output(), "flag -%s is required\n", name)
+			fs.Usage()
+			return errUsage
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/patrickmao1/zuika/circuits"
+	"github.com/patrickmao1/zuika/sui"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing")
+
+	require.Equal(t, exitUsage, run(nil))
+	require.Equal(t, exitOK, run([]string{"help"}))
+	require.Equal(t, exitUsage, run([]string{"unknown"}))
+	require.Equal(t, exitUsage, run([]string{"setup", "-h"}))
+	require.Equal(t, exitUsage, run([]string{"setup", "-ccs", missing}))
+	require.Equal(t, exitUsage, run([]string{"verify", "-vk", missing, "-proof", missing, "-public", missing, "extra"}))
+	require.Equal(t, exitUsage, run([]string{"inspect"}))
+	require.Equal(t, exitFailure, run([]string{"export-solidity", "-vk", missing, "-out", filepath.Join(dir, "v.sol"),
+		"-manifest", missing}))
+	// Artifacts are never used unchecked
+	require.Equal(t, exitUsage, run([]string{"export-solidity", "-vk", missing, "-out", filepath.Join(dir, "v.sol")}))
+	require.Equal(t, exitUsage, run([]string{"verify", "-vk", missing, "-proof", missing, "-public", missing}))
+	require.Equal(t, exitUsage, run([]string{"prove", "-ccs", missing, "-pk", missing, "-committee", missing,
+		"-checkpoint", missing, "-committee-root", "0x01", "-proof", missing, "-public", missing}))
+	// The committee is checked against a trusted root
+	require.Equal(t, exitUsage, run([]string{"prove", "-ccs", missing, "-pk", missing, "-committee", missing,
+		"-checkpoint", missing, "-proof", missing, "-public", missing, "-manifest", missing}))
+	require.Equal(t, exitFailure, run([]string{"prove", "-ccs", missing, "-pk", missing, "-committee", missing,
+		"-checkpoint", missing, "-committee-root", "zz", "-proof", missing, "-public", missing, "-manifest", missing}))
+	require.Equal(t, exitUsage, run([]string{"serve", "-ccs", missing, "-pk", missing, "-dir", dir}))
+	require.Equal(t, exitUsage, run([]string{"ceremony-init", "-ccs", missing, "-phase1", missing, "-dir", dir}))
+	require.Equal(t, exitFailure, run([]string{"inspect", "-ccs", missing}))
+	require.Equal(t, exitUsage, run([]string{"ceremony-contribute", "-dir", dir}))
+	require.Equal(t, exitFailure, run([]string{"ceremony-contribute", "-dir", missing, "-name", "alice"}))
+	require.Equal(t, exitFailure, run([]string{"ceremony-seal", "-dir", dir, "-ccs", missing, "-beacon", "zz",
+		"-pk", missing, "-vk", missing, "-manifest", missing}))
+	require.Equal(t, exitUsage, run([]string{"relay", "-sui-rpc", "http://localhost:9000"}))
+}
+
+// TestCompileToVerify runs compile, setup, prove, verify and export-solidity on a committee of three
+func TestCompileToVerify(t *testing.T) {
+	const maxAuthorities = 3
+	dir := t.TempDir()
+	path := func(name string) string {
+		return filepath.Join(dir, name)
+	}
+
+	// Members 1 and 2 hold 10000 of 12000
+	committee := &sui.Committee{Epoch: 736}
+	var privs []*big.Int
+	for i := 0; i < maxAuthorities; i++ {
+		priv, err := rand.Int(rand.Reader, fr.Modulus())
+		require.NoError(t, err)
+		var pub bls12381.G2Affine
+		pub.ScalarMultiplicationBase(priv)
+		privs = append(privs, priv)
+		committee.Members = append(committee.Members, sui.CommitteeMember{PubKey: pub.Bytes(), Stake: uint64(2000 * (i + 1))})
+	}
+	prev := sui.Digest{0xbb}
+	summary := sui.CheckpointSummary{
+		Epoch:          736,
+		SequenceNumber: 134973309,
+		ContentDigest:  sui.Digest{0xaa},
+		PreviousDigest: &prev,
+		TimestampMs:    1744911576632,
+	}
+	h, err := circuits.HashToG1(summary.SigningMessage())
+	require.NoError(t, err)
+	var agg bls12381.G1Jac
+	for _, i := range []int{1, 2} {
+		var sig bls12381.G1Affine
+		sig.ScalarMultiplication(&h, privs[i])
+		agg.AddMixed(&sig)
+	}
+	var sig bls12381.G1Affine
+	sig.FromJacobian(&agg)
+	checkpoint := &sui.CertifiedCheckpointSummary{
+		Summary: summary,
+		AuthSignature: sui.AuthorityQuorumSignInfo{
+			Epoch:      736,
+			Signature:  sig.Bytes(),
+			SignersMap: sui.EncodeSignersMap([]uint32{1, 2}),
+		},
+	}
+	b, err := json.Marshal(committee)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path("committee.json"), b, 0644))
+	require.NoError(t, os.WriteFile(path("checkpoint.bcs"), checkpoint.MarshalBCS(), 0644))
+	root, err := circuits.SuiCommitteeRoot(committee, maxAuthorities)
+	require.NoError(t, err)
+
+	params := []string{"-max-authorities", strconv.Itoa(maxAuthorities)}
+	require.Equal(t, exitOK, run(append([]string{"compile", "-ccs", path("ccs"), "-manifest", path("manifest.json")},
+		params...)))
+	require.Equal(t, exitOK, run([]string{"setup", "-ccs", path("ccs"), "-pk", path("pk"), "-vk", path("vk"),
+		"-manifest", path("manifest.json")}))
+	prove := []string{"prove", "-ccs", path("ccs"), "-pk", path("pk"), "-committee", path("committee.json"),
+		"-checkpoint", path("checkpoint.bcs"), "-proof", path("proof"), "-public", path("public"),
+		"-manifest", path("manifest.json")}
+	require.Equal(t, exitOK, run(append(append(prove, params...), "-committee-root", root.Text(16))))
+	require.Equal(t, exitOK, run([]string{"verify", "-vk", path("vk"), "-proof", path("proof"), "-public", path("public"),
+		"-manifest", path("manifest.json")}))
+	require.Equal(t, exitOK, run([]string{"export-solidity", "-vk", path("vk"), "-out", path("Verifier.sol"),
+		"-manifest", path("manifest.json")}))
+	require.FileExists(t, path("Verifier.sol"))
+
+	// Artifacts of other parameters or another committee are refused
+	require.Equal(t, exitFailure, run(append(append(prove, "-max-authorities", "4"), "-committee-root", root.Text(16))))
+	require.Equal(t, exitFailure, run(append(append(prove, params...), "-committee-root", "01")))
+}
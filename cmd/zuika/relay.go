@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"github.com/patrickmao1/zuika/prover"
+	"github.com/patrickmao1/zuika/relayer"
+	"github.com/patrickmao1/zuika/sui"
+	"github.com/patrickmao1/zuika/suiclient"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func runRelay(args []string) error {
+	fs := newFlagSet("relay")
+	suiRPC := fs.String("sui-rpc", "", "JSON-RPC URL of the Sui full node")
+	suiREST := fs.String("sui-rest", "", "REST API URL of the Sui full node, e.g. http://localhost:9000/v2")
+	proverURL := fs.String("prover", "", "URL of the prover service")
+	ethRPC := fs.String("eth-rpc", "", "JSON-RPC URL of the EVM node, which signs with the -from account")
+	contract := fs.String("contract", "", "address of ZKLightClient")
+	from := fs.String("from", "", "account the transactions are sent from")
+	progress := fs.String("progress", "", "path of the file the relay progress is persisted to")
+	poll := fs.Duration("poll", 10*time.Second, "how often the Sui node is polled for a new checkpoint")
+	receiptTimeout := fs.Duration("receipt-timeout", 10*time.Minute,
+		"how long a transaction is waited for before its checkpoint is relayed again")
+	if err := parseFlags(fs, args, "sui-rpc", "sui-rest", "prover", "eth-rpc", "contract", "from", "progress"); err != nil {
+		return err
+	}
+
+	r := relayer.New(relayer.Config{
+		Source:       &suiSource{c: &suiclient.Client{URL: *suiRPC, RESTURL: *suiREST}},
+		Prove:        (&prover.Client{URL: *proverURL}).Prove,
+		Eth:          &relayer.EthClient{URL: *ethRPC, ReceiptTimeout: *receiptTimeout},
+		Store:        &relayer.FileStore{Path: *progress},
+		Contract:     *contract,
+		From:         *from,
+		PollInterval: *poll,
+	})
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := r.Run(ctx); !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+// suiSource is the relayer source backed by a Sui full node
+type suiSource struct {
+	c *suiclient.Client
+}
+
+func (s *suiSource) LatestCheckpoint(ctx context.Context) (uint64, error) {
+	return s.c.GetLatestCheckpointSequenceNumber(ctx)
+}
+
+func (s *suiSource) Checkpoint(ctx context.Context, seq uint64) (*sui.CertifiedCheckpointSummary, error) {
+	return s.c.GetCertifiedCheckpoint(ctx, seq)
+}
+
+func (s *suiSource) Committee(ctx context.Context, epoch uint64) (*sui.Committee, error) {
+	return s.c.GetCommitteeInfo(ctx, epoch)
+}
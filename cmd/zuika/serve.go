@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/patrickmao1/zuika/artifacts"
+	"github.com/patrickmao1/zuika/prover"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func runServe(args []string) error {
+	fs := newFlagSet("serve")
+	p := paramsFlags(fs)
+	ccsPath := fs.String("ccs", "", "path of the constraint system")
+	pkPath := fs.String("pk", "", "path of the proving key")
+	manifestPath := fs.String("manifest", "", "path of the artifact manifest to check the artifacts against")
+	addr := fs.String("addr", "localhost:8080", "HTTP listen address")
+	dir := fs.String("dir", "", "directory the job queue is persisted to")
+	workers := fs.Int("workers", 1, "number of proofs computed concurrently")
+	maxQueued := fs.Int("max-queued", prover.DefaultMaxQueued, "max number of queued jobs")
+	if err := parseFlags(fs, args, "ccs", "pk", "dir", "manifest"); err != nil {
+		return err
+	}
+	params := artifacts.SigVerifyParams(*p)
+	files := map[string]string{artifacts.CCS: *ccsPath, artifacts.PK: *pkPath}
+	if _, err := loadManifest(*manifestPath, &params, files); err != nil {
+		return err
+	}
+
+	ccs := groth16.NewCS(ecc.BN254)
+	if err := readArtifact(*ccsPath, ccs); err != nil {
+		return err
+	}
+	pk := groth16.NewProvingKey(ecc.BN254)
+	if err := readArtifact(*pkPath, pk); err != nil {
+		return err
+	}
+	s, err := prover.NewServer(prover.Config{
+		Dir:       *dir,
+		Workers:   *workers,
+		MaxQueued: *maxQueued,
+		Prove:     prover.NewGroth16Prover(ccs, pk, *p),
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	srv := &http.Server{Addr: *addr, Handler: s.Handler()}
+	go func() {
+		<-ctx.Done()
+		srv.Shutdown(context.Background())
+	}()
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	fmt.Println("listening on", *addr)
+	err = srv.ListenAndServe()
+	stop()
+	<-done
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
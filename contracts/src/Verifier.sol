@@ -1,9 +1,8 @@
+
 // SPDX-License-Identifier: MIT
 
 pragma solidity ^0.8.0;
 
-import {console} from "forge-std/Test.sol";
-
 /// @title Groth16 verifier template.
 /// @author Remco Bloemen
 /// @notice Supports verifying Groth16 proofs. Proofs can be in uncompressed
@@ -11,6 +10,7 @@ import {console} from "forge-std/Test.sol";
 /// to compress proofs.
 /// @notice See <https://2π.com/23/bn254-compression> for further explanation.
 contract Verifier {
+
     /// Some of the provided public input values are larger than the field modulus.
     /// @dev Public input elements are not automatically reduced, as this is can be
     /// a dangerous source of bugs.
@@ -58,61 +58,79 @@ contract Verifier {
     uint256 constant EXP_SQRT_FP = 0xC19139CB84C680A6E14116DA060561765E05AA45A1C72A34F082305B61F3F52; // (P + 1) / 4;
 
     // Groth16 alpha point in G1
-    uint256 constant ALPHA_X = 14468630492399791827396498036441206548139591109146277370604153300602041919983;
-    uint256 constant ALPHA_Y = 19291291752341004457935586479022430480014436365713722557770393049389388233649;
+    uint256 constant ALPHA_X = 4220942762580646248686451634593608094182195162326211864795990539492260491498;
+    uint256 constant ALPHA_Y = 5759095352890222429009960631805071665645567994427093461976880203946991504054;
 
     // Groth16 beta point in G2 in powers of i
-    uint256 constant BETA_NEG_X_0 = 7712189175683701789984286230055252331765468003791285041292678659616615686641;
-    uint256 constant BETA_NEG_X_1 = 21833774387881212425313322562178345433912698292648620196872670467145344256856;
-    uint256 constant BETA_NEG_Y_0 = 8996142493722891600602955608404233593686734435017093743169443105889604975622;
-    uint256 constant BETA_NEG_Y_1 = 19023095304757289279279906054323445508270194216459944464644678976859224038760;
+    uint256 constant BETA_NEG_X_0 = 21191073183261993306106568928953389844304272663048669056162410762955296718293;
+    uint256 constant BETA_NEG_X_1 = 16181356835460981760901372115252087986117691754709860622803953845718445472056;
+    uint256 constant BETA_NEG_Y_0 = 4354647083427590027907275540395117521267504442257336008907317994037418953329;
+    uint256 constant BETA_NEG_Y_1 = 8799911823595916788950862366162077701359643401353206305278170520443780117677;
 
     // Groth16 gamma point in G2 in powers of i
-    uint256 constant GAMMA_NEG_X_0 = 17596465825886533180131181175214443020560626229265159196782659258978296445812;
-    uint256 constant GAMMA_NEG_X_1 = 654999523256329648338819215808556673042867247575131010555853802735057421137;
-    uint256 constant GAMMA_NEG_Y_0 = 11705666710886534642893124100866978364584952207031676315045097163224462266190;
-    uint256 constant GAMMA_NEG_Y_1 = 18873459108000855466234190817599858154228369710993031551756964657680716453900;
+    uint256 constant GAMMA_NEG_X_0 = 7902074542532910263979964380325408609277015833528791339716480647260799542978;
+    uint256 constant GAMMA_NEG_X_1 = 17333137661399447210228842818872412222401734801657314637535873385878848419049;
+    uint256 constant GAMMA_NEG_Y_0 = 9407866318837886952223815492171048363301128082974338529507509885566126431125;
+    uint256 constant GAMMA_NEG_Y_1 = 15488916748411533253918729567743325292145606154514237015870803549960222357802;
 
     // Groth16 delta point in G2 in powers of i
-    uint256 constant DELTA_NEG_X_0 = 3457471314916533497550703759970579317532540203509491828508333540916867929214;
-    uint256 constant DELTA_NEG_X_1 = 19741352194840941685916199709371051107471430633737935296078700707406841841603;
-    uint256 constant DELTA_NEG_Y_0 = 3592152018272166372136263545951466647255936797692081069491261599876578382738;
-    uint256 constant DELTA_NEG_Y_1 = 1146063837465820453728582539851346491714995951125381665536541461439269757752;
+    uint256 constant DELTA_NEG_X_0 = 12971411904290055240024523660301702541015203942891990585077129886731492452856;
+    uint256 constant DELTA_NEG_X_1 = 12243854013459317237509739044462922143211040058467080845239198917707701836556;
+    uint256 constant DELTA_NEG_Y_0 = 20738860918403092915924361244781331573703497146996723031913016781884781402214;
+    uint256 constant DELTA_NEG_Y_1 = 10162591492785169343048882471714991875075966531655607696118678964404893750314;
     // Pedersen G point in G2 in powers of i
-    uint256 constant PEDERSEN_G_X_0 = 9048367512696059173552733084483466903573535871807461375907294930870072457679;
-    uint256 constant PEDERSEN_G_X_1 = 21587437785816850555979162101499104180768127658618349508742198539476193365851;
-    uint256 constant PEDERSEN_G_Y_0 = 16957507693362885282961123091678391252055862975870522225035159086830123281884;
-    uint256 constant PEDERSEN_G_Y_1 = 2396640633921248627549306130897817927515297998674089057222672559034934828360;
+    uint256 constant PEDERSEN_G_X_0 = 17134947760342396818656238258379488563755725349155222869018521362605263208069;
+    uint256 constant PEDERSEN_G_X_1 = 10816019871324837557560540868069525040669327126782676782770621968290191320396;
+    uint256 constant PEDERSEN_G_Y_0 = 6136040569908174641902400068668617263726692929229815617856870307608198022706;
+    uint256 constant PEDERSEN_G_Y_1 = 7976358941247151319578839769785985446350760067169683447566374890699340666948;
 
     // Pedersen GSigmaNeg point in G2 in powers of i
-    uint256 constant PEDERSEN_GSIGMANEG_X_0 =
-        16605911908117533796162533601942679562734559101604026463807316530399889412740;
-    uint256 constant PEDERSEN_GSIGMANEG_X_1 =
-        7088333613932258324238343929774022130240098965477775854901915623310101820608;
-    uint256 constant PEDERSEN_GSIGMANEG_Y_0 =
-        7136080147593139002225852682166064982160841080896494580413312502398586956675;
-    uint256 constant PEDERSEN_GSIGMANEG_Y_1 =
-        20637622153565734377821187068675699103431589890670299886950099191263262811576;
+    uint256 constant PEDERSEN_GSIGMANEG_X_0 = 17800224402229706133615204919094507540904305571054549414603064242336801449043;
+    uint256 constant PEDERSEN_GSIGMANEG_X_1 = 10661948029240359715782549678944860360530114968467734248556059262444384816609;
+    uint256 constant PEDERSEN_GSIGMANEG_Y_0 = 4988682075682760373259489477603263358982487191061349541464712527833755733185;
+    uint256 constant PEDERSEN_GSIGMANEG_Y_1 = 21324620294954408371392049739151273003293570494678917104147820502528375096875;
 
     // Constant and public input points
-    uint256 constant CONSTANT_X = 8781055701325225333610923412119911736989723656801796983806234367706892302752;
-    uint256 constant CONSTANT_Y = 7030811361404119986116572169373123496635294390266455156579486590462105183728;
-    uint256 constant PUB_0_X = 5159371718473727180671394367163213665707265941097029321888953185369236530911;
-    uint256 constant PUB_0_Y = 2611796805504875459871649128830306303173592895451183465033776159207787155031;
-    uint256 constant PUB_1_X = 19830857905340428393436761911699294597058394719733192193864424990640851371671;
-    uint256 constant PUB_1_Y = 21611079342181858283061096780932033653190226344354741515528376143288420665493;
-    uint256 constant PUB_2_X = 896075579323422929951834937135733473619441474082420127131886796983561788802;
-    uint256 constant PUB_2_Y = 14639090787501775759704823531500534097066445651384954408616683109110782279747;
-    uint256 constant PUB_3_X = 2731521633395980659644062331564112949096153563331950869571880093911299695573;
-    uint256 constant PUB_3_Y = 10638109849510000581090546923938403442906317638265066977910498777528843065831;
-    uint256 constant PUB_4_X = 7013448823839765563474886117784041712836809767933903646050619099430989438013;
-    uint256 constant PUB_4_Y = 18588022569122828765096286526971393125883622537038915102200254306763578682772;
-    uint256 constant PUB_5_X = 3997604237394791271108984799800418582648810900789788118740462963550532546146;
-    uint256 constant PUB_5_Y = 6930933798392463186079403384801634337180815308043555884761469365596433081195;
-    uint256 constant PUB_6_X = 10419680883103223356455112413535360629797421250137728762220802397165512924033;
-    uint256 constant PUB_6_Y = 19919547611452278384627726969641308177278206278959062356405964107471057987164;
-    uint256 constant PUB_7_X = 11878749044789353218244147633530656772983992219677351290244157892984565934148;
-    uint256 constant PUB_7_Y = 15867418263600116457959436083798674149172161921298176295417819212947119337444;
+    uint256 constant CONSTANT_X = 7466993692429682227586909738898613954011326059742086220009315490787187339714;
+    uint256 constant CONSTANT_Y = 11452570417547111343406460549425270248371935112315003495978509372605866358551;
+    uint256 constant PUB_0_X = 5848360024347508900054032429398283350546605647098736643593198676555595770810;
+    uint256 constant PUB_0_Y = 20119874562007943671027764038186073713009213542155559263471239845526087109433;
+    uint256 constant PUB_1_X = 18802562628723691754790966672303118766833932679830957974927699856624738842686;
+    uint256 constant PUB_1_Y = 19871349240160312238804539481343750270596846872883713475962779364649756890407;
+    uint256 constant PUB_2_X = 14886288237932042673778091142030190062628460103182960561671825377650759473017;
+    uint256 constant PUB_2_Y = 12082131816594878400493135370153686679891015445060487174834657136272122751158;
+    uint256 constant PUB_3_X = 17399629495239669908372823564433222173975494537455321470002512305122653144976;
+    uint256 constant PUB_3_Y = 12515276941689903315653685801077698892202968409441001232630536619067048614811;
+    uint256 constant PUB_4_X = 6388235149600564841916424687673875544253175660844590188883491795853131837170;
+    uint256 constant PUB_4_Y = 20724460966236330048196844773424988098589506711612340635337926081306660051514;
+    uint256 constant PUB_5_X = 21163951392163049766857268860917911004315763478043967602395370843900343991026;
+    uint256 constant PUB_5_Y = 16805553209814003527096903744388797484634921848102415705602888676624242099482;
+    uint256 constant PUB_6_X = 15846863074362125065712068413005788411192240471535375253010099287604814716444;
+    uint256 constant PUB_6_Y = 19829071099505885760147585661023139916387742341199898210084859448715415415756;
+    uint256 constant PUB_7_X = 15258901953892638984561786780138809428669840385598195831143185385390594310842;
+    uint256 constant PUB_7_Y = 20463445746469919929486430035482233990019265558681703555531217412395389902007;
+    uint256 constant PUB_8_X = 12275900719024825689558732173555817281298854634630319324159643170022317792076;
+    uint256 constant PUB_8_Y = 18454006541982143143831018290281425669865650453325314258397507466771507018220;
+    uint256 constant PUB_9_X = 15787816919900319231763016803886454529259604573771829007974051597831049747323;
+    uint256 constant PUB_9_Y = 17984019228824617948946817789043176477608743121059849808912005778213577221159;
+    uint256 constant PUB_10_X = 8957811464106042017834560174839513795347288775738356141868944618826511232129;
+    uint256 constant PUB_10_Y = 14377040159350029408222312969485017465938712686014666320129931760966636283304;
+    uint256 constant PUB_11_X = 20174301413341287404329423295399246510359770690854859162676722328726519262631;
+    uint256 constant PUB_11_Y = 6951918100169954075498873954459230408868574024775099060637405476528994791599;
+    uint256 constant PUB_12_X = 14075741012987438140175020933875869957783298145408901168821770021767182191658;
+    uint256 constant PUB_12_Y = 11471616649354455954849704912946947143452841195838182418569461253193515372823;
+    uint256 constant PUB_13_X = 3038890445710587416318881932095536017310970337045384990829625974369875358311;
+    uint256 constant PUB_13_Y = 2288720598359907486686972157125628505984941423470287459608861358671706727452;
+    uint256 constant PUB_14_X = 16104174685100771684111308798928018355462150310004651021288364268328578571389;
+    uint256 constant PUB_14_Y = 15215253628779759747668960175414316187807362228512492919595099055785631508088;
+    uint256 constant PUB_15_X = 16083100734498095796128826587009306122356294995713886794316248040325480068962;
+    uint256 constant PUB_15_Y = 12948087726702250952079858859965098387078792198094550592548588513413324592965;
+    uint256 constant PUB_16_X = 2375923023085393566868974250390311706040866211083026150892224997806648394550;
+    uint256 constant PUB_16_Y = 4350306107289262842833523211928135545533688741694432953843562484070299395030;
+    uint256 constant PUB_17_X = 9417438145642691636180384327755820703891463934585023949560575501896572846208;
+    uint256 constant PUB_17_Y = 20369052041612128084445613773038801145908031598292960093547897830942205320494;
+    uint256 constant PUB_18_X = 2080205900289972518947792188678907200096071448240224767608111230986895956981;
+    uint256 constant PUB_18_Y = 7427693118866270465893074116062801807856369819866896150502955461911984996482;
 
     /// Negation in Fp.
     /// @notice Returns a number x such that a + x = 0 in Fp.
@@ -216,7 +234,8 @@ contract Verifier {
 
         // Check result to make sure we found a root.
         // Note: this also fails if a0 or a1 is not reduced.
-        if (a0 != addmod(mulmod(x0, x0, P), negate(mulmod(x1, x1, P)), P) || a1 != mulmod(2, mulmod(x0, x1, P), P)) {
+        if (a0 != addmod(mulmod(x0, x0, P), negate(mulmod(x1, x1, P)), P)
+        ||  a1 != mulmod(2, mulmod(x0, x1, P), P)) {
             revert ProofInvalid();
         }
     }
@@ -292,10 +311,7 @@ contract Verifier {
     /// @return c0 The first half of the compresed point (x0 with two signal bits).
     /// @return c1 The second half of the compressed point (x1 unmodified).
     function compress_g2(uint256 x0, uint256 x1, uint256 y0, uint256 y1)
-        internal
-        view
-        returns (uint256 c0, uint256 c1)
-    {
+    internal view returns (uint256 c0, uint256 c1) {
         if (x0 >= P || x1 >= P || y0 >= P || y1 >= P) {
             // G2 point not in field.
             revert ProofInvalid();
@@ -310,11 +326,11 @@ contract Verifier {
         uint256 y0_pos;
         uint256 y1_pos;
         {
-            uint256 n3ab = mulmod(mulmod(x0, x1, P), P - 3, P);
+            uint256 n3ab = mulmod(mulmod(x0, x1, P), P-3, P);
             uint256 a_3 = mulmod(mulmod(x0, x0, P), x0, P);
             uint256 b_3 = mulmod(mulmod(x1, x1, P), x1, P);
             y0_pos = addmod(FRACTION_27_82_FP, addmod(a_3, mulmod(n3ab, x1, P), P), P);
-            y1_pos = negate(addmod(FRACTION_3_82_FP, addmod(b_3, mulmod(n3ab, x0, P), P), P));
+            y1_pos = negate(addmod(FRACTION_3_82_FP,  addmod(b_3, mulmod(n3ab, x0, P), P), P));
         }
 
         // Determine hint bit
@@ -328,10 +344,10 @@ contract Verifier {
         // Recover y
         (y0_pos, y1_pos) = sqrt_Fp2(y0_pos, y1_pos, hint);
         if (y0 == y0_pos && y1 == y1_pos) {
-            c0 = (x0 << 2) | (hint ? 2 : 0) | 0;
+            c0 = (x0 << 2) | (hint ? 2  : 0) | 0;
             c1 = x1;
         } else if (y0 == negate(y0_pos) && y1 == negate(y1_pos)) {
-            c0 = (x0 << 2) | (hint ? 2 : 0) | 1;
+            c0 = (x0 << 2) | (hint ? 2  : 0) | 1;
             c1 = x1;
         } else {
             // G1 point not on curve.
@@ -351,10 +367,7 @@ contract Verifier {
     /// @return y0 The real part of the Y coordinate.
     /// @return y1 The imaginary part of the Y coordinate.
     function decompress_g2(uint256 c0, uint256 c1)
-        internal
-        view
-        returns (uint256 x0, uint256 x1, uint256 y0, uint256 y1)
-    {
+    internal view returns (uint256 x0, uint256 x1, uint256 y0, uint256 y1) {
         // Note that X = (0, 0) is not on the curve since 0³ + 3/(9 + i) is not a square.
         // so we can use it to represent the point at infinity.
         if (c0 == 0 && c1 == 0) {
@@ -370,12 +383,12 @@ contract Verifier {
             revert ProofInvalid();
         }
 
-        uint256 n3ab = mulmod(mulmod(x0, x1, P), P - 3, P);
+        uint256 n3ab = mulmod(mulmod(x0, x1, P), P-3, P);
         uint256 a_3 = mulmod(mulmod(x0, x0, P), x0, P);
         uint256 b_3 = mulmod(mulmod(x1, x1, P), x1, P);
 
         y0 = addmod(FRACTION_27_82_FP, addmod(a_3, mulmod(n3ab, x1, P), P), P);
-        y1 = negate(addmod(FRACTION_3_82_FP, addmod(b_3, mulmod(n3ab, x0, P), P), P));
+        y1 = negate(addmod(FRACTION_3_82_FP,  addmod(b_3, mulmod(n3ab, x0, P), P), P));
 
         // Note: sqrt_Fp2 reverts if there is no solution, i.e. the point is not on the curve.
         // Note: (X³ + 3/(9 + i)) is irreducible in Fp2, so y can not be zero.
@@ -397,10 +410,11 @@ contract Verifier {
     /// @return x The X coordinate of the resulting G1 point.
     /// @return y The Y coordinate of the resulting G1 point.
     function publicInputMSM(
-        uint256[7] calldata input,
+        uint256[18] calldata input,
         uint256[1] memory publicCommitments,
         uint256[2] memory commitments
-    ) internal view returns (uint256 x, uint256 y) {
+    )
+    internal view returns (uint256 x, uint256 y) {
         // Note: The ECMUL precompile does not reject unreduced values, so we check this.
         // Note: Unrolling this loop does not cost much extra in code-size, the bulk of the
         //       code-size is in the PUB_ constants.
@@ -418,58 +432,135 @@ contract Verifier {
             mstore(add(f, 0x20), CONSTANT_Y)
             mstore(g, mload(commitments))
             mstore(add(g, 0x20), mload(add(commitments, 0x20)))
-            success := and(success, staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
+            success := and(success,  staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
             mstore(g, PUB_0_X)
             mstore(add(g, 0x20), PUB_0_Y)
-            s := calldataload(input)
+            s :=  calldataload(input)
             mstore(add(g, 0x40), s)
             success := and(success, lt(s, R))
             success := and(success, staticcall(gas(), PRECOMPILE_MUL, g, 0x60, g, 0x40))
             success := and(success, staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
             mstore(g, PUB_1_X)
             mstore(add(g, 0x20), PUB_1_Y)
-            s := calldataload(add(input, 32))
+            s :=  calldataload(add(input, 32))
             mstore(add(g, 0x40), s)
             success := and(success, lt(s, R))
             success := and(success, staticcall(gas(), PRECOMPILE_MUL, g, 0x60, g, 0x40))
             success := and(success, staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
             mstore(g, PUB_2_X)
             mstore(add(g, 0x20), PUB_2_Y)
-            s := calldataload(add(input, 64))
+            s :=  calldataload(add(input, 64))
             mstore(add(g, 0x40), s)
             success := and(success, lt(s, R))
             success := and(success, staticcall(gas(), PRECOMPILE_MUL, g, 0x60, g, 0x40))
             success := and(success, staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
             mstore(g, PUB_3_X)
             mstore(add(g, 0x20), PUB_3_Y)
-            s := calldataload(add(input, 96))
+            s :=  calldataload(add(input, 96))
             mstore(add(g, 0x40), s)
             success := and(success, lt(s, R))
             success := and(success, staticcall(gas(), PRECOMPILE_MUL, g, 0x60, g, 0x40))
             success := and(success, staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
             mstore(g, PUB_4_X)
             mstore(add(g, 0x20), PUB_4_Y)
-            s := calldataload(add(input, 128))
+            s :=  calldataload(add(input, 128))
             mstore(add(g, 0x40), s)
             success := and(success, lt(s, R))
             success := and(success, staticcall(gas(), PRECOMPILE_MUL, g, 0x60, g, 0x40))
             success := and(success, staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
             mstore(g, PUB_5_X)
             mstore(add(g, 0x20), PUB_5_Y)
-            s := calldataload(add(input, 160))
+            s :=  calldataload(add(input, 160))
             mstore(add(g, 0x40), s)
             success := and(success, lt(s, R))
             success := and(success, staticcall(gas(), PRECOMPILE_MUL, g, 0x60, g, 0x40))
             success := and(success, staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
             mstore(g, PUB_6_X)
             mstore(add(g, 0x20), PUB_6_Y)
-            s := calldataload(add(input, 192))
+            s :=  calldataload(add(input, 192))
             mstore(add(g, 0x40), s)
             success := and(success, lt(s, R))
             success := and(success, staticcall(gas(), PRECOMPILE_MUL, g, 0x60, g, 0x40))
             success := and(success, staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
             mstore(g, PUB_7_X)
             mstore(add(g, 0x20), PUB_7_Y)
+            s :=  calldataload(add(input, 224))
+            mstore(add(g, 0x40), s)
+            success := and(success, lt(s, R))
+            success := and(success, staticcall(gas(), PRECOMPILE_MUL, g, 0x60, g, 0x40))
+            success := and(success, staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
+            mstore(g, PUB_8_X)
+            mstore(add(g, 0x20), PUB_8_Y)
+            s :=  calldataload(add(input, 256))
+            mstore(add(g, 0x40), s)
+            success := and(success, lt(s, R))
+            success := and(success, staticcall(gas(), PRECOMPILE_MUL, g, 0x60, g, 0x40))
+            success := and(success, staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
+            mstore(g, PUB_9_X)
+            mstore(add(g, 0x20), PUB_9_Y)
+            s :=  calldataload(add(input, 288))
+            mstore(add(g, 0x40), s)
+            success := and(success, lt(s, R))
+            success := and(success, staticcall(gas(), PRECOMPILE_MUL, g, 0x60, g, 0x40))
+            success := and(success, staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
+            mstore(g, PUB_10_X)
+            mstore(add(g, 0x20), PUB_10_Y)
+            s :=  calldataload(add(input, 320))
+            mstore(add(g, 0x40), s)
+            success := and(success, lt(s, R))
+            success := and(success, staticcall(gas(), PRECOMPILE_MUL, g, 0x60, g, 0x40))
+            success := and(success, staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
+            mstore(g, PUB_11_X)
+            mstore(add(g, 0x20), PUB_11_Y)
+            s :=  calldataload(add(input, 352))
+            mstore(add(g, 0x40), s)
+            success := and(success, lt(s, R))
+            success := and(success, staticcall(gas(), PRECOMPILE_MUL, g, 0x60, g, 0x40))
+            success := and(success, staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
+            mstore(g, PUB_12_X)
+            mstore(add(g, 0x20), PUB_12_Y)
+            s :=  calldataload(add(input, 384))
+            mstore(add(g, 0x40), s)
+            success := and(success, lt(s, R))
+            success := and(success, staticcall(gas(), PRECOMPILE_MUL, g, 0x60, g, 0x40))
+            success := and(success, staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
+            mstore(g, PUB_13_X)
+            mstore(add(g, 0x20), PUB_13_Y)
+            s :=  calldataload(add(input, 416))
+            mstore(add(g, 0x40), s)
+            success := and(success, lt(s, R))
+            success := and(success, staticcall(gas(), PRECOMPILE_MUL, g, 0x60, g, 0x40))
+            success := and(success, staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
+            mstore(g, PUB_14_X)
+            mstore(add(g, 0x20), PUB_14_Y)
+            s :=  calldataload(add(input, 448))
+            mstore(add(g, 0x40), s)
+            success := and(success, lt(s, R))
+            success := and(success, staticcall(gas(), PRECOMPILE_MUL, g, 0x60, g, 0x40))
+            success := and(success, staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
+            mstore(g, PUB_15_X)
+            mstore(add(g, 0x20), PUB_15_Y)
+            s :=  calldataload(add(input, 480))
+            mstore(add(g, 0x40), s)
+            success := and(success, lt(s, R))
+            success := and(success, staticcall(gas(), PRECOMPILE_MUL, g, 0x60, g, 0x40))
+            success := and(success, staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
+            mstore(g, PUB_16_X)
+            mstore(add(g, 0x20), PUB_16_Y)
+            s :=  calldataload(add(input, 512))
+            mstore(add(g, 0x40), s)
+            success := and(success, lt(s, R))
+            success := and(success, staticcall(gas(), PRECOMPILE_MUL, g, 0x60, g, 0x40))
+            success := and(success, staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
+            mstore(g, PUB_17_X)
+            mstore(add(g, 0x20), PUB_17_Y)
+            s :=  calldataload(add(input, 544))
+            mstore(add(g, 0x40), s)
+            success := and(success, lt(s, R))
+            success := and(success, staticcall(gas(), PRECOMPILE_MUL, g, 0x60, g, 0x40))
+            success := and(success, staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
+            mstore(g, PUB_18_X)
+            mstore(add(g, 0x20), PUB_18_Y)
             s := mload(publicCommitments)
             mstore(add(g, 0x40), s)
             success := and(success, lt(s, R))
@@ -502,10 +593,11 @@ contract Verifier {
         uint256[2] calldata commitments,
         uint256[2] calldata commitmentPok
     )
-        public
-        view
-        returns (uint256[4] memory compressed, uint256[1] memory compressedCommitments, uint256 compressedCommitmentPok)
-    {
+    public view returns (
+        uint256[4] memory compressed,
+        uint256[1] memory compressedCommitments,
+        uint256 compressedCommitmentPok
+    ) {
         compressed[0] = compress_g1(proof[0], proof[1]);
         (compressed[2], compressed[1]) = compress_g2(proof[3], proof[2], proof[5], proof[4]);
         compressed[3] = compress_g1(proof[6], proof[7]);
@@ -528,7 +620,7 @@ contract Verifier {
         uint256[4] calldata compressedProof,
         uint256[1] calldata compressedCommitments,
         uint256 compressedCommitmentPok,
-        uint256[7] calldata input
+        uint256[18] calldata input
     ) public view {
         uint256[1] memory publicCommitments;
         uint256[2] memory commitments;
@@ -539,19 +631,26 @@ contract Verifier {
 
             uint256[] memory publicAndCommitmentCommitted;
 
-            publicCommitments[0] =
-                uint256(keccak256(abi.encodePacked(commitments[0], commitments[1], publicAndCommitmentCommitted))) % R;
+            publicCommitments[0] = uint256(
+                keccak256(
+                    abi.encodePacked(
+                        commitments[0],
+                        commitments[1],
+                        publicAndCommitmentCommitted
+                    )
+                )
+            ) % R;
             // Commitments
-            pairings[0] = commitments[0];
-            pairings[1] = commitments[1];
-            pairings[2] = PEDERSEN_GSIGMANEG_X_1;
-            pairings[3] = PEDERSEN_GSIGMANEG_X_0;
-            pairings[4] = PEDERSEN_GSIGMANEG_Y_1;
-            pairings[5] = PEDERSEN_GSIGMANEG_Y_0;
-            pairings[6] = Px;
-            pairings[7] = Py;
-            pairings[8] = PEDERSEN_G_X_1;
-            pairings[9] = PEDERSEN_G_X_0;
+            pairings[ 0] = commitments[0];
+            pairings[ 1] = commitments[1];
+            pairings[ 2] = PEDERSEN_GSIGMANEG_X_1;
+            pairings[ 3] = PEDERSEN_GSIGMANEG_X_0;
+            pairings[ 4] = PEDERSEN_GSIGMANEG_Y_1;
+            pairings[ 5] = PEDERSEN_GSIGMANEG_Y_0;
+            pairings[ 6] = Px;
+            pairings[ 7] = Py;
+            pairings[ 8] = PEDERSEN_G_X_1;
+            pairings[ 9] = PEDERSEN_G_X_0;
             pairings[10] = PEDERSEN_G_Y_1;
             pairings[11] = PEDERSEN_G_Y_0;
 
@@ -572,23 +671,27 @@ contract Verifier {
             (uint256 Ax, uint256 Ay) = decompress_g1(compressedProof[0]);
             (uint256 Bx0, uint256 Bx1, uint256 By0, uint256 By1) = decompress_g2(compressedProof[2], compressedProof[1]);
             (uint256 Cx, uint256 Cy) = decompress_g1(compressedProof[3]);
-            (uint256 Lx, uint256 Ly) = publicInputMSM(input, publicCommitments, commitments);
+            (uint256 Lx, uint256 Ly) = publicInputMSM(
+                input,
+                publicCommitments,
+                commitments
+            );
 
             // Verify the pairing
             // Note: The precompile expects the F2 coefficients in big-endian order.
             // Note: The pairing precompile rejects unreduced values, so we won't check that here.
             // e(A, B)
-            pairings[0] = Ax;
-            pairings[1] = Ay;
-            pairings[2] = Bx1;
-            pairings[3] = Bx0;
-            pairings[4] = By1;
-            pairings[5] = By0;
+            pairings[ 0] = Ax;
+            pairings[ 1] = Ay;
+            pairings[ 2] = Bx1;
+            pairings[ 3] = Bx0;
+            pairings[ 4] = By1;
+            pairings[ 5] = By0;
             // e(C, -δ)
-            pairings[6] = Cx;
-            pairings[7] = Cy;
-            pairings[8] = DELTA_NEG_X_1;
-            pairings[9] = DELTA_NEG_X_0;
+            pairings[ 6] = Cx;
+            pairings[ 7] = Cy;
+            pairings[ 8] = DELTA_NEG_X_1;
+            pairings[ 9] = DELTA_NEG_X_0;
             pairings[10] = DELTA_NEG_Y_1;
             pairings[11] = DELTA_NEG_Y_0;
             // e(α, -β)
@@ -635,14 +738,21 @@ contract Verifier {
         uint256[8] calldata proof,
         uint256[2] calldata commitments,
         uint256[2] calldata commitmentPok,
-        uint256[7] calldata input
+        uint256[18] calldata input
     ) public view {
         // HashToField
         uint256[1] memory publicCommitments;
         uint256[] memory publicAndCommitmentCommitted;
 
-        publicCommitments[0] =
-            uint256(keccak256(abi.encodePacked(commitments[0], commitments[1], publicAndCommitmentCommitted))) % R;
+            publicCommitments[0] = uint256(
+                keccak256(
+                    abi.encodePacked(
+                        commitments[0],
+                        commitments[1],
+                        publicAndCommitmentCommitted
+                    )
+                )
+            ) % R;
 
         // Verify pedersen commitments
         bool success;
@@ -667,11 +777,11 @@ contract Verifier {
             revert CommitmentInvalid();
         }
 
-        for (uint256 i = 0; i < input.length; i++) {
-            console.logBytes32(bytes32(input[i]));
-        }
-
-        (uint256 x, uint256 y) = publicInputMSM(input, publicCommitments, commitments);
+        (uint256 x, uint256 y) = publicInputMSM(
+            input,
+            publicCommitments,
+            commitments
+        );
 
         // Note: The precompile expects the F2 coefficients in big-endian order.
         // Note: The pairing precompile rejects unreduced values, so we won't check that here.
@@ -10,8 +10,10 @@ contract ZKLightClient {
     struct CheckpointData {
         uint64 epochId;
         uint64 sequenceNumber;
-        uint64 contentDigest;
-        uint64 previousDigest;
+        uint64 networkTotalTransactions;
+        bytes32 contentDigest;
+        bytes32 previousDigest;
+        uint64 timestampMs;
     }
 
     event Verified(CheckpointData data);
@@ -19,25 +21,54 @@ contract ZKLightClient {
     BLS12381 public bls;
     address public zkVerifier;
     bytes4 public immutable verifyProofSelector;
+    // If false, the circuit computes expand_message_xmd itself (SigVerifyCircuit.ExpandInCircuit) and the expanded
+    // message is not part of the public inputs
+    bool public immutable expandInContract;
     bytes32 public currentCommitteeRoot;
+    uint256 public currentCommitteeStake;
 
-    constructor(address _zkVerifier, address _bls) {
+    // _committeeRoot and _committeeStake are the CommitteeRoot and TotalStake of the trusted committee, see
+    // circuits.SuiCommitteeRoot
+    constructor(
+        address _zkVerifier,
+        address _bls,
+        bool _expandInContract,
+        bytes32 _committeeRoot,
+        uint256 _committeeStake
+    ) {
         zkVerifier = _zkVerifier;
         bls = BLS12381(_bls);
-        verifyProofSelector = bytes4(keccak256("verifyProof(uint256[8],uint256[2],uint256[2],uint256[7])"));
-        currentCommitteeRoot = hex"1cf2542241bf7df9dd50fc28db1eb8104d7c293d6f53378d17d9688327c7afbb"; // for testing
+        expandInContract = _expandInContract;
+        verifyProofSelector = _expandInContract
+            ? bytes4(keccak256("verifyProof(uint256[8],uint256[2],uint256[2],uint256[18])"))
+            : bytes4(keccak256("verifyProof(uint256[8],uint256[2],uint256[2],uint256[12])"));
+        currentCommitteeRoot = _committeeRoot;
+        currentCommitteeStake = _committeeStake;
     }
 
     function updateCheckpoint(bytes calldata checkpointIntent, bytes memory zkProof) public {
-        bytes memory xmd = bls.expandMessage(checkpointIntent);
-        uint256[3] memory fp0 = bytesToLimbs(Bytes.slice(xmd, 0, 64));
-        uint256[3] memory fp1 = bytesToLimbs(Bytes.slice(xmd, 64, 128));
-
-        bool pass = verifyProof(zkProof, abi.encodePacked(fp0, fp1, currentCommitteeRoot));
+        bool pass = verifyProof(zkProof, publicInputs(checkpointIntent));
         require(pass, "invalid sig");
 
+        emit Verified(extractCheckpointData(checkpointIntent));
+    }
+
+    // Packs the public inputs of SigVerifyCircuit for checkpointIntent against the current committee. Mirrored by
+    // circuits.PublicInputs, test/vectors/public_inputs.json is shared by the tests of both.
+    function publicInputs(bytes calldata checkpointIntent) public view returns (bytes memory) {
+        bytes memory expanded;
+        if (expandInContract) {
+            bytes memory xmd = bls.expandMessage(checkpointIntent);
+            uint256[3] memory fp0 = bytesToLimbs(Bytes.slice(xmd, 0, 64));
+            uint256[3] memory fp1 = bytesToLimbs(Bytes.slice(xmd, 64, 128));
+            expanded = abi.encodePacked(fp0, fp1);
+        }
+
+        uint256[2] memory intentHash = digestToLimbs(sha256(checkpointIntent));
         CheckpointData memory data = extractCheckpointData(checkpointIntent);
-        emit Verified(data);
+        return abi.encodePacked(
+            expanded, currentCommitteeRoot, currentCommitteeStake, intentHash, checkpointDataToInputs(data)
+        );
     }
 
     function bytesToLimbs(bytes memory b) internal pure returns (uint256[3] memory limbs) {
@@ -47,15 +78,45 @@ contract ZKLightClient {
         return limbs;
     }
 
+    function digestToLimbs(bytes32 digest) internal pure returns (uint256[2] memory limbs) {
+        limbs[0] = uint256(digest) >> 128;
+        limbs[1] = uint256(uint128(uint256(digest)));
+        return limbs;
+    }
+
+    // Decodes the fixed-offset fields of intent || bcs(CheckpointSummary) || epoch. The circuit asserts the same
+    // layout (intent, digest length prefixes, previous_digest being Some) on the signed bytes.
     function extractCheckpointData(bytes calldata checkpoint) internal pure returns (CheckpointData memory data) {
         data = CheckpointData(
-            uint64(bytes8(checkpoint[0:8])),
-            uint64(bytes8(checkpoint[8:16])),
-            uint64(bytes8(checkpoint[8:16])),
-            uint64(bytes8(checkpoint[16:24]))
+            readUint64LE(checkpoint, 3),
+            readUint64LE(checkpoint, 11),
+            readUint64LE(checkpoint, 19),
+            bytes32(checkpoint[28:60]),
+            bytes32(checkpoint[62:94]),
+            readUint64LE(checkpoint, 126)
         );
     }
 
+    function checkpointDataToInputs(CheckpointData memory data) internal pure returns (uint256[8] memory inputs) {
+        uint256[2] memory contentDigest = digestToLimbs(data.contentDigest);
+        uint256[2] memory previousDigest = digestToLimbs(data.previousDigest);
+        inputs[0] = data.epochId;
+        inputs[1] = data.sequenceNumber;
+        inputs[2] = data.networkTotalTransactions;
+        inputs[3] = contentDigest[0];
+        inputs[4] = contentDigest[1];
+        inputs[5] = previousDigest[0];
+        inputs[6] = previousDigest[1];
+        inputs[7] = data.timestampMs;
+        return inputs;
+    }
+
+    function readUint64LE(bytes calldata b, uint256 offset) internal pure returns (uint64 v) {
+        for (uint256 i = 0; i < 8; i++) {
+            v |= uint64(uint8(b[offset + i])) << uint64(8 * i);
+        }
+    }
+
     function verifyProof(bytes memory proof, bytes memory input) public view returns (bool) {
         (bool success,) = zkVerifier.staticcall(abi.encodePacked(verifyProofSelector, proof, input));
         return success;
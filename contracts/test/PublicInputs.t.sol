@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity ^0.8.29;
+
+import "../src/BLS12381.sol";
+import {Test} from "forge-std/Test.sol";
+import {ZKLightClient} from "../src/ZKLightClient.sol";
+
+// Checks publicInputs against the vectors generated by circuits.TestPublicInputsVectors from circuits.PublicInputs
+contract PublicInputsTest is Test {
+    BLS12381 public bls;
+
+    function setUp() public {
+        bls = new BLS12381();
+    }
+
+    function test_publicInputsVectors() public {
+        string memory json = vm.readFile(string.concat(vm.projectRoot(), "/test/vectors/public_inputs.json"));
+        uint256 n;
+        for (; vm.keyExistsJson(json, string.concat(".vectors[", vm.toString(n), "]")); n++) {
+            string memory key = string.concat(".vectors[", vm.toString(n), "]");
+            ZKLightClient lightClient = new ZKLightClient(
+                address(0),
+                address(bls),
+                vm.parseJsonBool(json, string.concat(key, ".expandInContract")),
+                vm.parseJsonBytes32(json, string.concat(key, ".committeeRoot")),
+                vm.parseJsonUint(json, string.concat(key, ".totalStake"))
+            );
+            assertEq(
+                lightClient.publicInputs(vm.parseJsonBytes(json, string.concat(key, ".checkpointIntent"))),
+                vm.parseJsonBytes(json, string.concat(key, ".publicInputs")),
+                vm.parseJsonString(json, string.concat(key, ".name"))
+            );
+        }
+        assertGt(n, 0);
+    }
+}
@@ -0,0 +1,160 @@
+// Package lightclient follows Sui checkpoints off-chain the way ZKLightClient does on-chain, verifying the committee
+// signatures natively instead of with a proof. It starts from a trusted committee and moves to the next committee at
+// each end-of-epoch checkpoint.
+package lightclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/patrickmao1/zuika/sui"
+	"os"
+	"sync"
+)
+
+var (
+	ErrNotNewer = errors.New("checkpoint not newer than the latest")
+	ErrLinkage  = errors.New("previous digest mismatch")
+)
+
+// TrustedCheckpoint is the latest verified checkpoint
+type TrustedCheckpoint struct {
+	Epoch          uint64     `json:"epoch"`
+	SequenceNumber uint64     `json:"sequenceNumber"`
+	Digest         sui.Digest `json:"digest"`
+	TimestampMs    uint64     `json:"timestampMs"`
+}
+
+// State is what the light client persists
+type State struct {
+	// Committee of the current epoch
+	Committee *sui.Committee `json:"committee"`
+	// Nil until the first checkpoint is verified
+	Latest *TrustedCheckpoint `json:"latest,omitempty"`
+}
+
+type LightClient struct {
+	mu    sync.Mutex
+	path  string
+	state State
+}
+
+// New returns a light client trusting committee that does not persist its state
+func New(committee *sui.Committee) *LightClient {
+	return &LightClient{state: State{Committee: committee}}
+}
+
+// Open returns a light client persisting its state to path. The state in path is resumed if the file exists,
+// otherwise the client starts from trusted.
+func Open(path string, trusted *sui.Committee) (*LightClient, error) {
+	c := &LightClient{path: path}
+	b, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if trusted == nil {
+			return nil, fmt.Errorf("%s does not exist and no trusted committee is given", path)
+		}
+		c.state.Committee = trusted
+		if err := c.save(); err != nil {
+			return nil, err
+		}
+		return c, nil
+	case err != nil:
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &c.state); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if c.state.Committee == nil {
+		return nil, fmt.Errorf("%s: no committee", path)
+	}
+	return c, nil
+}
+
+// State returns a copy of the current state
+func (c *LightClient) State() State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := State{Committee: &sui.Committee{Epoch: c.state.Committee.Epoch}}
+	s.Committee.Members = append(s.Committee.Members, c.state.Committee.Members...)
+	if c.state.Latest != nil {
+		latest := *c.state.Latest
+		s.Latest = &latest
+	}
+	return s
+}
+
+// Verify checks that checkpoint would be accepted by Update without updating the state
+func (c *LightClient) Verify(checkpoint *sui.CertifiedCheckpointSummary) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.verify(checkpoint)
+}
+
+// Update verifies checkpoint and makes it the latest checkpoint. Checkpoints must come with increasing sequence
+// numbers, and a checkpoint directly following the latest must link to it with its previous digest. Checkpoints may be
+// skipped within an epoch but not across epochs: the end-of-epoch checkpoint is needed to learn the next committee.
+func (c *LightClient) Update(checkpoint *sui.CertifiedCheckpointSummary) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.verify(checkpoint); err != nil {
+		return err
+	}
+
+	summary := &checkpoint.Summary
+	next := State{
+		Committee: c.state.Committee,
+		Latest: &TrustedCheckpoint{
+			Epoch:          summary.Epoch,
+			SequenceNumber: summary.SequenceNumber,
+			Digest:         summary.Digest(),
+			TimestampMs:    summary.TimestampMs,
+		},
+	}
+	if summary.EndOfEpochData != nil {
+		committee, err := sui.NextCommittee(summary)
+		if err != nil {
+			return err
+		}
+		if committee.TotalStake() == 0 {
+			return fmt.Errorf("checkpoint %d: next committee has no stake", summary.SequenceNumber)
+		}
+		next.Committee = committee
+	}
+	prev := c.state
+	c.state = next
+	if err := c.save(); err != nil {
+		c.state = prev
+		return err
+	}
+	return nil
+}
+
+func (c *LightClient) verify(checkpoint *sui.CertifiedCheckpointSummary) error {
+	summary := &checkpoint.Summary
+	if latest := c.state.Latest; latest != nil {
+		if summary.SequenceNumber <= latest.SequenceNumber {
+			return fmt.Errorf("%w: checkpoint %d, latest %d", ErrNotNewer, summary.SequenceNumber, latest.SequenceNumber)
+		}
+		if summary.SequenceNumber == latest.SequenceNumber+1 &&
+			(summary.PreviousDigest == nil || *summary.PreviousDigest != latest.Digest) {
+			return fmt.Errorf("%w: checkpoint %d does not follow %s", ErrLinkage, summary.SequenceNumber, latest.Digest)
+		}
+	}
+	return VerifyCertifiedCheckpoint(c.state.Committee, checkpoint)
+}
+
+func (c *LightClient) save() error {
+	if c.path == "" {
+		return nil
+	}
+	b, err := json.MarshalIndent(&c.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
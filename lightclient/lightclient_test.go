@@ -0,0 +1,179 @@
+package lightclient
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/patrickmao1/zuika/circuits"
+	"github.com/patrickmao1/zuika/sui"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+type testCommittee struct {
+	*sui.Committee
+	privs []*big.Int
+}
+
+func newTestCommittee(t *testing.T, epoch uint64, stakes ...uint64) *testCommittee {
+	c := &testCommittee{Committee: &sui.Committee{Epoch: epoch}}
+	for _, stake := range stakes {
+		priv, err := rand.Int(rand.Reader, fr.Modulus())
+		require.NoError(t, err)
+		var pub bls12381.G2Affine
+		pub.ScalarMultiplicationBase(priv)
+		c.privs = append(c.privs, priv)
+		c.Members = append(c.Members, sui.CommitteeMember{PubKey: pub.Bytes(), Stake: stake})
+	}
+	return c
+}
+
+// sign certifies summary with the signatures of signers
+func (c *testCommittee) sign(t *testing.T, summary sui.CheckpointSummary, signers ...uint32) *sui.CertifiedCheckpointSummary {
+	h, err := circuits.HashToG1(summary.SigningMessage())
+	require.NoError(t, err)
+	var agg bls12381.G1Jac
+	for _, s := range signers {
+		var sig bls12381.G1Affine
+		sig.ScalarMultiplication(&h, c.privs[s])
+		agg.AddMixed(&sig)
+	}
+	var sig bls12381.G1Affine
+	sig.FromJacobian(&agg)
+	return &sui.CertifiedCheckpointSummary{
+		Summary: summary,
+		AuthSignature: sui.AuthorityQuorumSignInfo{
+			Epoch:      c.Epoch,
+			Signature:  sig.Bytes(),
+			SignersMap: sui.EncodeSignersMap(signers),
+		},
+	}
+}
+
+func testSummary(epoch, seq uint64, prev sui.Digest) sui.CheckpointSummary {
+	return sui.CheckpointSummary{
+		Epoch:          epoch,
+		SequenceNumber: seq,
+		ContentDigest:  sui.Digest{byte(seq)},
+		PreviousDigest: &prev,
+		TimestampMs:    1744911576632 + seq,
+	}
+}
+
+func TestVerifyCertifiedCheckpoint(t *testing.T) {
+	c := newTestCommittee(t, 5, 2000, 2000, 1000, 1000)
+	summary := testSummary(5, 100, sui.Digest{})
+
+	require.NoError(t, VerifyCertifiedCheckpoint(c.Committee, c.sign(t, summary, 0, 1, 3)))
+	require.NoError(t, VerifyCertifiedCheckpoint(c.Committee, c.sign(t, summary, 0, 1, 2, 3)))
+	// Exactly two thirds is not a quorum
+	require.ErrorIs(t, VerifyCertifiedCheckpoint(c.Committee, c.sign(t, summary, 0, 1)), ErrNoQuorum)
+
+	// The signers map must match the signers
+	forged := c.sign(t, summary, 0, 1, 2)
+	forged.AuthSignature.SignersMap = sui.EncodeSignersMap([]uint32{0, 1, 3})
+	require.ErrorIs(t, VerifyCertifiedCheckpoint(c.Committee, forged), ErrInvalidSignature)
+	forged.AuthSignature.SignersMap = sui.EncodeSignersMap([]uint32{0, 1, 2, 4})
+	require.ErrorIs(t, VerifyCertifiedCheckpoint(c.Committee, forged), ErrInvalidSignature)
+
+	// The signature must be over the summary
+	forged = c.sign(t, summary, 0, 1, 2)
+	forged.Summary.TimestampMs++
+	require.ErrorIs(t, VerifyCertifiedCheckpoint(c.Committee, forged), ErrInvalidSignature)
+
+	other := newTestCommittee(t, 6, 1, 1, 1)
+	require.ErrorIs(t, VerifyCertifiedCheckpoint(other.Committee, c.sign(t, summary, 0, 1, 2)), ErrWrongEpoch)
+}
+
+// TestMainnetCheckpoint verifies checkpoint 134973309 against the committee of epoch 736, from the suiclient test data
+func TestMainnetCheckpoint(t *testing.T) {
+	b, err := os.ReadFile("../suiclient/testdata/checkpoint_134973309.bcs")
+	require.NoError(t, err)
+	checkpoint := new(sui.CertifiedCheckpointSummary)
+	require.NoError(t, checkpoint.UnmarshalBCS(b))
+
+	b, err = os.ReadFile("../suiclient/testdata/suix_getCommitteeInfo_736.json")
+	require.NoError(t, err)
+	var res struct {
+		Result struct {
+			Validators [][2]string `json:"validators"`
+		} `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(b, &res))
+	committee := &sui.Committee{Epoch: 736}
+	for _, v := range res.Result.Validators {
+		var pubkey []byte
+		require.NoError(t, json.Unmarshal([]byte(strconv.Quote(v[0])), &pubkey))
+		stake, err := strconv.ParseUint(v[1], 10, 64)
+		require.NoError(t, err)
+		m := sui.CommitteeMember{Stake: stake}
+		copy(m.PubKey[:], pubkey)
+		committee.Members = append(committee.Members, m)
+	}
+
+	lc := New(committee)
+	require.NoError(t, lc.Update(checkpoint))
+	require.Equal(t, "C9gQ2zhGhdfKZB6pze9BXYwT9pjsPKk94H5uHc7ZDeWJ", lc.State().Latest.Digest.String())
+
+	checkpoint.Summary.NetworkTotalTransactions++
+	require.ErrorIs(t, VerifyCertifiedCheckpoint(committee, checkpoint), ErrInvalidSignature)
+}
+
+func TestLightClient(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	c5 := newTestCommittee(t, 5, 2500, 2500, 2500, 2500)
+	lc, err := Open(path, c5.Committee)
+	require.NoError(t, err)
+
+	cp100 := c5.sign(t, testSummary(5, 100, sui.Digest{0xaa}), 0, 1, 2)
+	require.NoError(t, lc.Update(cp100))
+	d100 := cp100.Summary.Digest()
+
+	// Monotonic sequence numbers
+	require.ErrorIs(t, lc.Update(cp100), ErrNotNewer)
+	require.ErrorIs(t, lc.Update(c5.sign(t, testSummary(5, 99, sui.Digest{}), 0, 1, 2)), ErrNotNewer)
+
+	// A checkpoint directly following the latest must link to it
+	require.ErrorIs(t, lc.Update(c5.sign(t, testSummary(5, 101, sui.Digest{0xbb}), 0, 1, 2)), ErrLinkage)
+	cp101 := c5.sign(t, testSummary(5, 101, d100), 0, 1, 2)
+	require.NoError(t, lc.Verify(cp101))
+	require.NoError(t, lc.Update(cp101))
+
+	// Checkpoints may be skipped within an epoch
+	cp110 := c5.sign(t, testSummary(5, 110, sui.Digest{0xcc}), 1, 2, 3)
+	require.NoError(t, lc.Update(cp110))
+
+	// The end-of-epoch checkpoint rotates the committee
+	c6 := newTestCommittee(t, 6, 5000, 3000, 2000)
+	eoe := testSummary(5, 111, cp110.Summary.Digest())
+	eoe.EndOfEpochData = &sui.EndOfEpochData{NextEpochCommittee: c6.Members, NextEpochProtocolVersion: 70}
+	require.NoError(t, lc.Update(c5.sign(t, eoe, 0, 2, 3)))
+	require.Equal(t, uint64(6), lc.State().Committee.Epoch)
+
+	require.ErrorIs(t, lc.Update(c5.sign(t, testSummary(5, 120, sui.Digest{}), 0, 1, 2)), ErrWrongEpoch)
+	require.ErrorIs(t, lc.Update(c6.sign(t, testSummary(6, 112, sui.Digest{}), 0, 1)), ErrLinkage)
+	cp112 := c6.sign(t, testSummary(6, 112, eoe.Digest()), 0, 1)
+	require.NoError(t, lc.Update(cp112))
+
+	// The state survives a restart, the trusted committee is then ignored
+	lc, err = Open(path, c5.Committee)
+	require.NoError(t, err)
+	state := lc.State()
+	require.Equal(t, c6.Committee, state.Committee)
+	require.Equal(t, &TrustedCheckpoint{
+		Epoch:          6,
+		SequenceNumber: 112,
+		Digest:         cp112.Summary.Digest(),
+		TimestampMs:    cp112.Summary.TimestampMs,
+	}, state.Latest)
+	require.ErrorIs(t, lc.Update(cp112), ErrNotNewer)
+	require.NoError(t, lc.Update(c6.sign(t, testSummary(6, 113, cp112.Summary.Digest()), 0, 2)))
+
+	_, err = Open(filepath.Join(t.TempDir(), "missing.json"), nil)
+	require.Error(t, err)
+}
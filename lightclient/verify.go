@@ -0,0 +1,79 @@
+package lightclient
+
+import (
+	"errors"
+	"fmt"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/patrickmao1/zuika/circuits"
+	"github.com/patrickmao1/zuika/sui"
+)
+
+var (
+	ErrWrongEpoch       = errors.New("wrong epoch")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrNoQuorum         = errors.New("no quorum")
+)
+
+// VerifyCertifiedCheckpoint checks that checkpoint is signed by a quorum of committee: the signers map must select
+// members holding more than circuits.DefaultQuorumNumerator/DefaultQuorumDenominator of the stake, and the aggregated
+// signature must verify against the sum of their public keys
+func VerifyCertifiedCheckpoint(committee *sui.Committee, checkpoint *sui.CertifiedCheckpointSummary) error {
+	summary := &checkpoint.Summary
+	if summary.Epoch != committee.Epoch || checkpoint.AuthSignature.Epoch != committee.Epoch {
+		return fmt.Errorf("%w: checkpoint of epoch %d signed in epoch %d, committee of epoch %d",
+			ErrWrongEpoch, summary.Epoch, checkpoint.AuthSignature.Epoch, committee.Epoch)
+	}
+	signers, err := sui.DecodeSignersMap(checkpoint.AuthSignature.SignersMap)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+
+	var signed uint64
+	var aggPubKey bls12381.G2Jac
+	for _, s := range signers {
+		if int(s) >= len(committee.Members) {
+			return fmt.Errorf("%w: signer %d out of committee of size %d", ErrInvalidSignature, s, len(committee.Members))
+		}
+		m := &committee.Members[s]
+		var pubkey bls12381.G2Affine
+		if _, err := pubkey.SetBytes(m.PubKey[:]); err != nil {
+			return fmt.Errorf("member %d: %w", s, err)
+		}
+		aggPubKey.AddMixed(&pubkey)
+		signed += m.Stake
+	}
+	total := committee.TotalStake()
+	if !circuits.HasQuorum(signed, total, circuits.DefaultQuorumNumerator, circuits.DefaultQuorumDenominator) {
+		return fmt.Errorf("%w: signed stake %d of %d", ErrNoQuorum, signed, total)
+	}
+
+	sig, err := checkpoint.AuthSignature.G1()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	ok, err := verifyAggregate(summary.SigningMessage(), &sig, &aggPubKey)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: aggregated signature of %d signers does not verify", ErrInvalidSignature, len(signers))
+	}
+	return nil
+}
+
+// verifyAggregate checks e(sig, g2) == e(H(msg), aggPubKey)
+func verifyAggregate(msg []byte, sig *bls12381.G1Affine, aggPubKey *bls12381.G2Jac) (bool, error) {
+	var pubkey bls12381.G2Affine
+	pubkey.FromJacobian(aggPubKey)
+	if pubkey.IsInfinity() || sig.IsInfinity() {
+		return false, nil
+	}
+	h, err := circuits.HashToG1(msg)
+	if err != nil {
+		return false, err
+	}
+	_, _, _, g2 := bls12381.Generators()
+	var negG2 bls12381.G2Affine
+	negG2.Neg(&g2)
+	return bls12381.PairingCheck([]bls12381.G1Affine{*sig, h}, []bls12381.G2Affine{negG2, pubkey})
+}
@@ -0,0 +1,82 @@
+package prover
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+const maxRequestSize = 1 << 20
+
+// Handler serves the HTTP API:
+//
+//	POST /jobs             submit a SubmitRequest, returns the queued Job
+//	GET  /jobs/{id}        poll the Job
+//	GET  /jobs/{id}/proof  fetch the Proof of a done job
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /jobs", s.handleSubmit)
+	mux.HandleFunc("GET /jobs/{id}", s.handleJob)
+	mux.HandleFunc("GET /jobs/{id}/proof", s.handleProof)
+	return mux
+}
+
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	req := new(SubmitRequest)
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	job, err := s.Submit(req)
+	switch {
+	case errors.Is(err, ErrQueueFull):
+		writeError(w, http.StatusServiceUnavailable, err)
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err)
+	default:
+		writeResponse(w, http.StatusAccepted, job)
+	}
+}
+
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request) {
+	job, err := s.Job(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, job)
+}
+
+func (s *Server) handleProof(w http.ResponseWriter, r *http.Request) {
+	job, proof, err := s.Proof(r.PathValue("id"))
+	switch {
+	case errors.Is(err, ErrNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+	case job.Status == StatusFailed:
+		writeError(w, http.StatusConflict, fmt.Errorf("job failed: %s", job.Error))
+	case proof == nil:
+		writeError(w, http.StatusConflict, fmt.Errorf("job is %s", job.Status))
+	default:
+		writeResponse(w, http.StatusOK, proof)
+	}
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeResponse(w, status, errorResponse{Error: err.Error()})
+}
+
+func writeResponse(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("writing response: %v", err)
+	}
+}
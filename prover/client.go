@@ -0,0 +1,109 @@
+package prover
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/patrickmao1/zuika/sui"
+	"math/big"
+	"net/http"
+	"time"
+)
+
+// Client talks to a prover service over its HTTP API
+type Client struct {
+	URL  string
+	HTTP *http.Client
+	// How often a submitted job is polled, five seconds if zero
+	PollInterval time.Duration
+}
+
+// Prove submits a job and waits for its proof. It has the signature of ProveFunc so that a remote service can be
+// used in place of an in-process prover.
+func (c *Client) Prove(ctx context.Context, committee *sui.Committee, checkpoint *sui.CertifiedCheckpointSummary, committeeRoot *big.Int) (*Proof, error) {
+	if committeeRoot == nil {
+		return nil, errors.New("missing committee root")
+	}
+	req := &SubmitRequest{
+		Committee:     committee,
+		Checkpoint:    hex.EncodeToString(checkpoint.MarshalBCS()),
+		CommitteeRoot: committeeRoot.Text(16),
+	}
+	job := new(Job)
+	if err := c.do(ctx, http.MethodPost, "/jobs", req, http.StatusAccepted, job); err != nil {
+		return nil, err
+	}
+	interval := c.PollInterval
+	if interval == 0 {
+		interval = 5 * time.Second
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+		if err := c.do(ctx, http.MethodGet, "/jobs/"+job.ID, nil, http.StatusOK, job); err != nil {
+			return nil, err
+		}
+		switch job.Status {
+		case StatusDone:
+			proof := new(Proof)
+			if err := c.do(ctx, http.MethodGet, "/jobs/"+job.ID+"/proof", nil, http.StatusOK, proof); err != nil {
+				return nil, err
+			}
+			return proof, nil
+		case StatusFailed:
+			if err, ok := precheckErrors[job.ErrorCode]; ok {
+				return nil, fmt.Errorf("job %s failed: %w", job.ID, &jobError{msg: job.Error, err: err})
+			}
+			return nil, fmt.Errorf("job %s failed: %s", job.ID, job.Error)
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body any, status int, resp any) error {
+	var b bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&b).Encode(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.URL+path, &b)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != status {
+		var e errorResponse
+		json.NewDecoder(res.Body).Decode(&e)
+		return fmt.Errorf("%s %s: %s: %s", method, path, res.Status, e.Error)
+	}
+	return json.NewDecoder(res.Body).Decode(resp)
+}
+
+// jobError is the error a job failed with, matching the precheck error it was reported with
+type jobError struct {
+	msg string
+	err error
+}
+
+func (e *jobError) Error() string {
+	return e.msg
+}
+
+func (e *jobError) Unwrap() error {
+	return e.err
+}
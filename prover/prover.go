@@ -0,0 +1,73 @@
+// Package prover is a long-running service proving certified checkpoints with SigVerifyCircuit. It keeps the
+// constraint system and proving key in memory and works through a queue of jobs persisted to disk.
+package prover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/solidity"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/patrickmao1/zuika/circuits"
+	"github.com/patrickmao1/zuika/sui"
+	"github.com/patrickmao1/zuika/utils"
+	"math/big"
+)
+
+// Proof is a proof in the layout of utils.ExportProofForSolidity along with the public inputs it was proven for
+type Proof struct {
+	Proof         [8]*big.Int `json:"proof"`
+	Commitments   [2]*big.Int `json:"commitments"`
+	CommitmentPok [2]*big.Int `json:"commitmentPok"`
+	PublicInputs  []*big.Int  `json:"publicInputs"`
+}
+
+// ProveFunc proves that checkpoint is certified by committee. committeeRoot is the CommitteeRoot the proof is going to be
+// verified against, a committee not matching it is refused before proving.
+type ProveFunc func(ctx context.Context, committee *sui.Committee, checkpoint *sui.CertifiedCheckpointSummary, committeeRoot *big.Int) (*Proof, error)
+
+// NewGroth16Prover returns a ProveFunc proving SigVerifyCircuit compiled with p into ccs
+func NewGroth16Prover(ccs constraint.ConstraintSystem, pk groth16.ProvingKey, p circuits.SigVerifyParams) ProveFunc {
+	return func(ctx context.Context, committee *sui.Committee, checkpoint *sui.CertifiedCheckpointSummary, committeeRoot *big.Int) (*Proof, error) {
+		if committeeRoot == nil {
+			return nil, errors.New("missing committee root")
+		}
+		if err := circuits.Precheck(committee, checkpoint, p, committeeRoot); err != nil {
+			return nil, err
+		}
+		assignment, err := circuits.NewSigVerifyAssignment(committee, checkpoint, p)
+		if err != nil {
+			return nil, err
+		}
+		w, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+		if err != nil {
+			return nil, err
+		}
+		pub, err := w.Public()
+		if err != nil {
+			return nil, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		proof, err := groth16.Prove(ccs, pk, w, solidity.WithProverTargetSolidityVerifier(backend.GROTH16))
+		if err != nil {
+			return nil, err
+		}
+		inputs, ok := pub.Vector().(fr.Vector)
+		if !ok {
+			return nil, fmt.Errorf("unexpected public witness type %T", pub.Vector())
+		}
+		res := &Proof{PublicInputs: make([]*big.Int, len(inputs))}
+		res.Proof, res.Commitments, res.CommitmentPok = utils.ExportProofForSolidity(proof)
+		for i := range inputs {
+			res.PublicInputs[i] = inputs[i].BigInt(new(big.Int))
+		}
+		return res, nil
+	}
+}
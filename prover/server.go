@@ -0,0 +1,372 @@
+package prover
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/patrickmao1/zuika/circuits"
+	"github.com/patrickmao1/zuika/sui"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusQueued  Status = "queued"
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+)
+
+const DefaultMaxQueued = 64
+
+var (
+	ErrQueueFull = errors.New("queue is full")
+	ErrNotFound  = errors.New("job not found")
+)
+
+type Job struct {
+	ID             string `json:"id"`
+	Status         Status `json:"status"`
+	Epoch          uint64 `json:"epoch"`
+	SequenceNumber uint64 `json:"sequenceNumber"`
+	Error          string `json:"error,omitempty"`
+	// Set when the job failed with one of the errors of precheckErrors, so that clients can match it
+	ErrorCode string    `json:"errorCode,omitempty"`
+	Created   time.Time `json:"created"`
+	Updated   time.Time `json:"updated"`
+}
+
+// precheckErrors are the errors of circuits.Precheck a failed job reports by code. They tell that the request cannot
+// be proven, as opposed to a failure of the prover.
+var precheckErrors = map[string]error{
+	"bad_signer_map":          circuits.ErrBadSignerMap,
+	"insufficient_stake":      circuits.ErrInsufficientStake,
+	"committee_root_mismatch": circuits.ErrCommitteeRootMismatch,
+	"bad_aggregate_signature": circuits.ErrBadAggregateSignature,
+}
+
+func errorCode(err error) string {
+	for code, e := range precheckErrors {
+		if errors.Is(err, e) {
+			return code
+		}
+	}
+	return ""
+}
+
+// SubmitRequest is the body of POST /jobs
+type SubmitRequest struct {
+	Committee *sui.Committee `json:"committee"`
+	// Hex encoded BCS of the CertifiedCheckpointSummary
+	Checkpoint string `json:"checkpoint"`
+	// Hex encoded CommitteeRoot the proof is going to be verified against, e.g. ZKLightClient.currentCommitteeRoot
+	CommitteeRoot string `json:"committeeRoot"`
+}
+
+func (r *SubmitRequest) decode() (*sui.Committee, *sui.CertifiedCheckpointSummary, *big.Int, error) {
+	if r.Committee == nil {
+		return nil, nil, nil, errors.New("missing committee")
+	}
+	b, err := hex.DecodeString(strings.TrimPrefix(r.Checkpoint, "0x"))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("checkpoint: %w", err)
+	}
+	checkpoint := new(sui.CertifiedCheckpointSummary)
+	if err := checkpoint.UnmarshalBCS(b); err != nil {
+		return nil, nil, nil, fmt.Errorf("checkpoint: %w", err)
+	}
+	if r.Committee.Epoch != checkpoint.Summary.Epoch {
+		return nil, nil, nil, fmt.Errorf("committee of epoch %d cannot certify checkpoint of epoch %d",
+			r.Committee.Epoch, checkpoint.Summary.Epoch)
+	}
+	if r.CommitteeRoot == "" {
+		return nil, nil, nil, errors.New("missing committee root")
+	}
+	root, ok := new(big.Int).SetString(strings.TrimPrefix(r.CommitteeRoot, "0x"), 16)
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("invalid committee root %q", r.CommitteeRoot)
+	}
+	return r.Committee, checkpoint, root, nil
+}
+
+type Config struct {
+	// Directory the queue is persisted to
+	Dir string
+	// Number of proofs computed concurrently, 1 if zero. Each of them holds a full witness and prover state in memory.
+	Workers int
+	// Submissions are refused while this many jobs are queued, DefaultMaxQueued if zero
+	MaxQueued int
+	Prove     ProveFunc
+}
+
+// Server owns the job queue. Jobs left queued or running by a previous process are queued again by NewServer.
+type Server struct {
+	cfg Config
+
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	pending []string
+	wake    chan struct{}
+}
+
+func NewServer(cfg Config) (*Server, error) {
+	if cfg.Prove == nil {
+		return nil, errors.New("nil ProveFunc")
+	}
+	if cfg.Workers == 0 {
+		cfg.Workers = 1
+	}
+	if cfg.MaxQueued == 0 {
+		cfg.MaxQueued = DefaultMaxQueued
+	}
+	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+		return nil, err
+	}
+	s := &Server{cfg: cfg, jobs: map[string]*Job{}, wake: make(chan struct{}, 1)}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Server) load() error {
+	paths, err := filepath.Glob(filepath.Join(s.cfg.Dir, "*.job.json"))
+	if err != nil {
+		return err
+	}
+	var pending []*Job
+	for _, path := range paths {
+		job := new(Job)
+		if err := readJSON(path, job); err != nil {
+			return err
+		}
+		s.jobs[job.ID] = job
+		if job.Status == StatusQueued || job.Status == StatusRunning {
+			pending = append(pending, job)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Created.Before(pending[j].Created) })
+	for _, job := range pending {
+		if job.Status == StatusRunning {
+			log.Printf("requeueing job %s interrupted while running", job.ID)
+			job.Status = StatusQueued
+			if err := s.saveJob(job); err != nil {
+				return err
+			}
+		}
+		s.pending = append(s.pending, job.ID)
+	}
+	return nil
+}
+
+// Submit validates and queues a request
+func (s *Server) Submit(req *SubmitRequest) (*Job, error) {
+	_, checkpoint, _, err := req.decode()
+	if err != nil {
+		return nil, err
+	}
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	job := &Job{
+		ID:             hex.EncodeToString(id[:]),
+		Status:         StatusQueued,
+		Epoch:          checkpoint.Summary.Epoch,
+		SequenceNumber: checkpoint.Summary.SequenceNumber,
+		Created:        now,
+		Updated:        now,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending) >= s.cfg.MaxQueued {
+		return nil, ErrQueueFull
+	}
+	if err := writeJSON(s.path(job.ID, "request"), req); err != nil {
+		return nil, err
+	}
+	if err := s.saveJob(job); err != nil {
+		return nil, err
+	}
+	s.jobs[job.ID] = job
+	s.pending = append(s.pending, job.ID)
+	s.signal()
+	c := *job
+	return &c, nil
+}
+
+// Job returns a copy of the job with the given id
+func (s *Server) Job(id string) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c := *job
+	return &c, nil
+}
+
+// Proof returns the proof of a done job
+func (s *Server) Proof(id string) (*Job, *Proof, error) {
+	job, err := s.Job(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Status != StatusDone {
+		return job, nil, nil
+	}
+	proof := new(Proof)
+	if err := readJSON(s.path(id, "proof"), proof); err != nil {
+		return nil, nil, err
+	}
+	return job, proof, nil
+}
+
+// Run proves queued jobs with cfg.Workers workers until ctx is done, then waits for the running proofs
+func (s *Server) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < s.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *Server) work(ctx context.Context) {
+	for {
+		job := s.next()
+		if job == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.wake:
+				continue
+			}
+		}
+		s.run(ctx, job)
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// next pops the oldest pending job and marks it running
+func (s *Server) next() *Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending) == 0 {
+		return nil
+	}
+	job := s.jobs[s.pending[0]]
+	s.pending = s.pending[1:]
+	if len(s.pending) > 0 {
+		// Let other idle workers pick up the rest
+		s.signal()
+	}
+	s.update(job, StatusRunning, nil)
+	c := *job
+	return &c
+}
+
+func (s *Server) run(ctx context.Context, job *Job) {
+	proof, err := s.prove(ctx, job)
+	if err == nil {
+		err = writeJSON(s.path(job.ID, "proof"), proof)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case err == nil:
+		s.update(s.jobs[job.ID], StatusDone, nil)
+	case ctx.Err() != nil:
+		// Left running on disk so that the next process picks it up again
+		log.Printf("job %s interrupted: %v", job.ID, err)
+	default:
+		log.Printf("job %s failed: %v", job.ID, err)
+		s.update(s.jobs[job.ID], StatusFailed, err)
+	}
+}
+
+func (s *Server) prove(ctx context.Context, job *Job) (*Proof, error) {
+	req := new(SubmitRequest)
+	if err := readJSON(s.path(job.ID, "request"), req); err != nil {
+		return nil, err
+	}
+	committee, checkpoint, committeeRoot, err := req.decode()
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	proof, err := s.cfg.Prove(ctx, committee, checkpoint, committeeRoot)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("proved checkpoint %d of epoch %d in %s", job.SequenceNumber, job.Epoch, time.Since(start))
+	return proof, nil
+}
+
+// update sets the status of job and persists it, s.mu must be held
+func (s *Server) update(job *Job, status Status, err error) {
+	job.Status, job.Error, job.ErrorCode, job.Updated = status, "", "", time.Now().UTC()
+	if err != nil {
+		job.Error, job.ErrorCode = err.Error(), errorCode(err)
+	}
+	if err := s.saveJob(job); err != nil {
+		log.Printf("persisting job %s: %v", job.ID, err)
+	}
+}
+
+func (s *Server) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Server) saveJob(job *Job) error {
+	return writeJSON(s.path(job.ID, "job"), job)
+}
+
+func (s *Server) path(id, kind string) string {
+	return filepath.Join(s.cfg.Dir, id+"."+kind+".json")
+}
+
+func writeJSON(path string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func readJSON(path string, v any) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package prover
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/patrickmao1/zuika/circuits"
+	"github.com/patrickmao1/zuika/sui"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var testCommitteeRoot = big.NewInt(0x1234)
+
+func testRequest(seq uint64) *SubmitRequest {
+	checkpoint := &sui.CertifiedCheckpointSummary{
+		Summary:       sui.CheckpointSummary{Epoch: 5, SequenceNumber: seq},
+		AuthSignature: sui.AuthorityQuorumSignInfo{Epoch: 5},
+	}
+	return &SubmitRequest{
+		Committee:     &sui.Committee{Epoch: 5, Members: []sui.CommitteeMember{{Stake: 1}}},
+		Checkpoint:    hex.EncodeToString(checkpoint.MarshalBCS()),
+		CommitteeRoot: "0x1234",
+	}
+}
+
+// fakeProver proves a checkpoint as its sequence number once released, against testCommitteeRoot only
+type fakeProver struct {
+	started chan uint64
+	release chan error
+}
+
+func newFakeProver() *fakeProver {
+	return &fakeProver{started: make(chan uint64, 16), release: make(chan error)}
+}
+
+func (p *fakeProver) prove(ctx context.Context, _ *sui.Committee, checkpoint *sui.CertifiedCheckpointSummary, committeeRoot *big.Int) (*Proof, error) {
+	if committeeRoot.Cmp(testCommitteeRoot) != 0 {
+		return nil, fmt.Errorf("committee root %x", committeeRoot)
+	}
+	seq := checkpoint.Summary.SequenceNumber
+	p.started <- seq
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case err := <-p.release:
+		if err != nil {
+			return nil, err
+		}
+	}
+	proof := &Proof{PublicInputs: []*big.Int{new(big.Int).SetUint64(seq)}}
+	for i := range proof.Proof {
+		proof.Proof[i] = big.NewInt(int64(i))
+	}
+	proof.Commitments = [2]*big.Int{big.NewInt(1), big.NewInt(2)}
+	proof.CommitmentPok = [2]*big.Int{big.NewInt(3), big.NewInt(4)}
+	return proof, nil
+}
+
+func do(t *testing.T, method, url string, body any, status int, resp any) {
+	var b bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&b).Encode(body))
+	}
+	req, err := http.NewRequest(method, url, &b)
+	require.NoError(t, err)
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, status, res.StatusCode)
+	if resp != nil {
+		require.NoError(t, json.NewDecoder(res.Body).Decode(resp))
+	}
+}
+
+func waitStatus(t *testing.T, s *Server, id string, status Status) {
+	require.Eventually(t, func() bool {
+		job, err := s.Job(id)
+		require.NoError(t, err)
+		return job.Status == status
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestServer(t *testing.T) {
+	p := newFakeProver()
+	s, err := NewServer(Config{Dir: t.TempDir(), Workers: 1, MaxQueued: 2, Prove: p.prove})
+	require.NoError(t, err)
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	var job1, job2 Job
+	do(t, "POST", srv.URL+"/jobs", testRequest(10), http.StatusAccepted, &job1)
+	do(t, "POST", srv.URL+"/jobs", testRequest(11), http.StatusAccepted, &job2)
+	do(t, "POST", srv.URL+"/jobs", testRequest(12), http.StatusServiceUnavailable, nil)
+	require.Equal(t, StatusQueued, job1.Status)
+	require.Equal(t, uint64(10), job1.SequenceNumber)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// A single worker proves one job at a time
+	require.Equal(t, uint64(10), <-p.started)
+	waitStatus(t, s, job1.ID, StatusRunning)
+	var job Job
+	do(t, "GET", srv.URL+"/jobs/"+job2.ID, nil, http.StatusOK, &job)
+	require.Equal(t, StatusQueued, job.Status)
+	do(t, "GET", srv.URL+"/jobs/"+job1.ID+"/proof", nil, http.StatusConflict, nil)
+
+	p.release <- nil
+	require.Equal(t, uint64(11), <-p.started)
+	p.release <- errors.New("boom")
+
+	waitStatus(t, s, job1.ID, StatusDone)
+	var proof Proof
+	do(t, "GET", srv.URL+"/jobs/"+job1.ID+"/proof", nil, http.StatusOK, &proof)
+	require.Equal(t, "10", proof.PublicInputs[0].String())
+	require.Equal(t, "7", proof.Proof[7].String())
+
+	waitStatus(t, s, job2.ID, StatusFailed)
+	do(t, "GET", srv.URL+"/jobs/"+job2.ID, nil, http.StatusOK, &job)
+	require.Equal(t, "boom", job.Error)
+	do(t, "GET", srv.URL+"/jobs/"+job2.ID+"/proof", nil, http.StatusConflict, nil)
+
+	do(t, "GET", srv.URL+"/jobs/unknown", nil, http.StatusNotFound, nil)
+	do(t, "GET", srv.URL+"/jobs/unknown/proof", nil, http.StatusNotFound, nil)
+}
+
+func TestServerSubmitInvalid(t *testing.T) {
+	s, err := NewServer(Config{Dir: t.TempDir(), Prove: newFakeProver().prove})
+	require.NoError(t, err)
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	req := testRequest(1)
+	req.Committee.Epoch = 4
+	do(t, "POST", srv.URL+"/jobs", req, http.StatusBadRequest, nil)
+	req = testRequest(1)
+	req.Checkpoint = "0x00"
+	do(t, "POST", srv.URL+"/jobs", req, http.StatusBadRequest, nil)
+	req = testRequest(1)
+	req.CommitteeRoot = ""
+	do(t, "POST", srv.URL+"/jobs", req, http.StatusBadRequest, nil)
+	req.CommitteeRoot = "0xzz"
+	do(t, "POST", srv.URL+"/jobs", req, http.StatusBadRequest, nil)
+	do(t, "POST", srv.URL+"/jobs", "not a request", http.StatusBadRequest, nil)
+}
+
+func TestServerRestart(t *testing.T) {
+	dir := t.TempDir()
+	p := newFakeProver()
+	s, err := NewServer(Config{Dir: dir, Prove: p.prove})
+	require.NoError(t, err)
+	running, err := s.Submit(testRequest(20))
+	require.NoError(t, err)
+	queued, err := s.Submit(testRequest(21))
+	require.NoError(t, err)
+
+	// The process stops while proving the first job
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	require.Equal(t, uint64(20), <-p.started)
+	cancel()
+	<-done
+
+	s, err = NewServer(Config{Dir: dir, Prove: p.prove})
+	require.NoError(t, err)
+	for _, id := range []string{running.ID, queued.ID} {
+		job, err := s.Job(id)
+		require.NoError(t, err)
+		require.Equal(t, StatusQueued, job.Status)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+	for _, seq := range []uint64{20, 21} {
+		require.Equal(t, seq, <-p.started)
+		p.release <- nil
+	}
+	waitStatus(t, s, running.ID, StatusDone)
+	waitStatus(t, s, queued.ID, StatusDone)
+	_, proof, err := s.Proof(queued.ID)
+	require.NoError(t, err)
+	require.Equal(t, "21", proof.PublicInputs[0].String())
+}
+
+func TestClient(t *testing.T) {
+	p := newFakeProver()
+	s, err := NewServer(Config{Dir: t.TempDir(), Prove: p.prove})
+	require.NoError(t, err)
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	c := &Client{URL: srv.URL, PollInterval: time.Millisecond}
+	req := testRequest(30)
+	committee, checkpoint, root, err := req.decode()
+	require.NoError(t, err)
+	require.Equal(t, testCommitteeRoot, root)
+	go func() {
+		<-p.started
+		p.release <- nil
+		<-p.started
+		p.release <- errors.New("boom")
+	}()
+	proof, err := c.Prove(ctx, committee, checkpoint, root)
+	require.NoError(t, err)
+	require.Equal(t, "30", proof.PublicInputs[0].String())
+	_, err = c.Prove(ctx, committee, checkpoint, root)
+	require.ErrorContains(t, err, "boom")
+
+	// Precheck errors are reported as such
+	go func() {
+		<-p.started
+		p.release <- fmt.Errorf("%w: committee of epoch 5", circuits.ErrCommitteeRootMismatch)
+	}()
+	_, err = c.Prove(ctx, committee, checkpoint, root)
+	require.ErrorIs(t, err, circuits.ErrCommitteeRootMismatch)
+	require.ErrorContains(t, err, "committee of epoch 5")
+}
@@ -0,0 +1,164 @@
+package relayer
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrTxReverted = errors.New("transaction reverted")
+	// The transaction was not mined within EthClient.ReceiptTimeout, e.g. because it was dropped from the mempool or
+	// underpriced
+	ErrTxDropped = errors.New("transaction not mined")
+)
+
+// EthClient is a minimal Ethereum JSON-RPC client. Transactions are sent with eth_sendTransaction, so From must be an
+// account the node (or a signing proxy in front of it) can sign for.
+type EthClient struct {
+	URL  string
+	HTTP *http.Client
+	// How often eth_getTransactionReceipt is polled, one second if zero
+	ReceiptPollInterval time.Duration
+	// How long a transaction is waited for before it is considered dropped, ten minutes if zero
+	ReceiptTimeout time.Duration
+
+	id atomic.Uint64
+}
+
+type rpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      uint64 `json:"id"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("json-rpc error %d: %s", e.Code, e.Message)
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+func (c *EthClient) call(ctx context.Context, result any, method string, params ...any) error {
+	body, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: c.id.Add(1), Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: http status %s", method, res.Status)
+	}
+	var resp rpcResponse
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("%s: %w", method, resp.Error)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Result, result)
+}
+
+type sendTxArgs struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Data string `json:"data"`
+}
+
+// SendTransaction sends a transaction calling to with data and returns its hash
+func (c *EthClient) SendTransaction(ctx context.Context, from, to string, data []byte) (string, error) {
+	var hash string
+	err := c.call(ctx, &hash, "eth_sendTransaction", sendTxArgs{From: from, To: to, Data: hexBytes(data)})
+	return hash, err
+}
+
+type callArgs struct {
+	To   string `json:"to"`
+	Data string `json:"data"`
+}
+
+// Call executes a call of to with data against the latest block and returns its output
+func (c *EthClient) Call(ctx context.Context, to string, data []byte) ([]byte, error) {
+	var out string
+	if err := c.call(ctx, &out, "eth_call", callArgs{To: to, Data: hexBytes(data)}, "latest"); err != nil {
+		return nil, err
+	}
+	return parseHexBytes(out)
+}
+
+type receipt struct {
+	Status      string `json:"status"`
+	BlockNumber string `json:"blockNumber"`
+}
+
+// WaitMined polls the receipt of the transaction until it is mined and returns ErrTxReverted if it failed, or
+// ErrTxDropped if it is not mined within ReceiptTimeout
+func (c *EthClient) WaitMined(ctx context.Context, hash string) error {
+	interval := c.ReceiptPollInterval
+	if interval == 0 {
+		interval = time.Second
+	}
+	timeout := c.ReceiptTimeout
+	if timeout == 0 {
+		timeout = 10 * time.Minute
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		var r *receipt
+		if err := c.call(ctx, &r, "eth_getTransactionReceipt", hash); err != nil {
+			return err
+		}
+		if r != nil {
+			if r.Status != "0x1" {
+				return fmt.Errorf("%w: %s", ErrTxReverted, hash)
+			}
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w after %s: %s", ErrTxDropped, timeout, hash)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+func hexBytes(b []byte) string {
+	return "0x" + hex.EncodeToString(b)
+}
+
+func parseHexBytes(s string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(s, "0x"))
+}
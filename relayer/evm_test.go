@@ -0,0 +1,339 @@
+package relayer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/patrickmao1/zuika/prover"
+	"github.com/patrickmao1/zuika/sui"
+	"github.com/patrickmao1/zuika/utils"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	contractsDir = "../contracts"
+	// proofVectorsPath is shared with contracts/test/ZKLightClient.t.sol
+	proofVectorsPath = contractsDir + "/test/vectors/proofs.json"
+	// Gas limit of the transactions sent through simEVM, enough to deploy BLS12381 and to expand the message in
+	// updateCheckpoint
+	simGasLimit = 15_000_000
+)
+
+// proofVector is a proof of a certified checkpoint made with the proving key of Verifier.sol, or of
+// VerifierExpandInCircuit.sol if expandInContract is false
+type proofVector struct {
+	Name             string        `json:"name"`
+	CheckpointIntent hexutil.Bytes `json:"checkpointIntent"`
+	CommitteeRoot    hexutil.Bytes `json:"committeeRoot"`
+	TotalStake       uint64        `json:"totalStake,string"`
+	ExpandInContract bool          `json:"expandInContract"`
+	Proof            hexutil.Bytes `json:"proof"`
+}
+
+func readProofVectors(t *testing.T) []proofVector {
+	b, err := os.ReadFile(proofVectorsPath)
+	require.NoError(t, err)
+	var vectors struct {
+		Proofs []proofVector `json:"proofs"`
+	}
+	require.NoError(t, json.Unmarshal(b, &vectors))
+	require.NotEmpty(t, vectors.Proofs)
+	return vectors.Proofs
+}
+
+// creationCode returns the creation code of the contract built by forge from contracts/src/<name>.sol
+func creationCode(t *testing.T, name string) []byte {
+	b, err := os.ReadFile(filepath.Join(contractsDir, "out", name+".sol", name+".json"))
+	require.NoError(t, err)
+	var artifact struct {
+		Bytecode struct {
+			Object string `json:"object"`
+		} `json:"bytecode"`
+	}
+	require.NoError(t, json.Unmarshal(b, &artifact))
+	code, err := parseHexBytes(artifact.Bytecode.Object)
+	require.NoError(t, err)
+	require.NotEmpty(t, code)
+	return code
+}
+
+// forgeBuild compiles the contracts, the test is skipped if forge is not installed
+func forgeBuild(t *testing.T) {
+	forge, err := exec.LookPath("forge")
+	if err != nil {
+		t.Skip("forge is needed to build the contracts")
+	}
+	cmd := exec.Command(forge, "build")
+	cmd.Dir = contractsDir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "%s", out)
+}
+
+// simEVM is a signing JSON-RPC proxy in front of a simulated chain running go-ethereum's EVM. It signs the
+// eth_sendTransaction of EthClient with the key of its only account and mines every transaction in its own block.
+type simEVM struct {
+	backend *simulated.Backend
+	key     *ecdsa.PrivateKey
+	from    common.Address
+	chainID *big.Int
+
+	mu sync.Mutex
+}
+
+func newSimEVM(t *testing.T) (*simEVM, *httptest.Server) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	balance := new(big.Int).Lsh(big.NewInt(1), 100)
+	backend := simulated.NewBackend(types.GenesisAlloc{from: {Balance: balance}}, simulated.WithBlockGasLimit(30_000_000))
+	t.Cleanup(func() { backend.Close() })
+	chainID, err := backend.Client().ChainID(context.Background())
+	require.NoError(t, err)
+	s := &simEVM{backend: backend, key: key, from: from, chainID: chainID}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return s, srv
+}
+
+func (s *simEVM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	serveJSONRPC(w, r, func(method string, params []json.RawMessage) (any, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.handle(r.Context(), method, params)
+	})
+}
+
+func (s *simEVM) handle(ctx context.Context, method string, params []json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, errors.New("missing params")
+	}
+	client := s.backend.Client()
+	switch method {
+	case "eth_sendTransaction":
+		var args sendTxArgs
+		if err := json.Unmarshal(params[0], &args); err != nil {
+			return nil, err
+		}
+		if !common.IsHexAddress(args.From) || common.HexToAddress(args.From) != s.from {
+			return nil, errors.New("unknown account " + args.From)
+		}
+		data, err := parseHexBytes(args.Data)
+		if err != nil {
+			return nil, err
+		}
+		to := common.HexToAddress(args.To)
+		tx, err := s.send(ctx, &to, data)
+		if err != nil {
+			return nil, err
+		}
+		return tx.Hash().Hex(), nil
+	case "eth_call":
+		var args callArgs
+		if err := json.Unmarshal(params[0], &args); err != nil {
+			return nil, err
+		}
+		data, err := parseHexBytes(args.Data)
+		if err != nil {
+			return nil, err
+		}
+		to := common.HexToAddress(args.To)
+		out, err := client.CallContract(ctx, ethereum.CallMsg{From: s.from, To: &to, Data: data}, nil)
+		if err != nil {
+			return nil, err
+		}
+		return hexBytes(out), nil
+	case "eth_getTransactionReceipt":
+		var hash string
+		if err := json.Unmarshal(params[0], &hash); err != nil {
+			return nil, err
+		}
+		r, err := client.TransactionReceipt(ctx, common.HexToHash(hash))
+		if errors.Is(err, ethereum.NotFound) {
+			return nil, nil
+		}
+		return r, err
+	}
+	return nil, &rpcError{Code: -32601, Message: "not found"}
+}
+
+// send signs and mines a transaction from the account of s, to nil deploys data. It returns once the receipt is
+// available.
+func (s *simEVM) send(ctx context.Context, to *common.Address, data []byte) (*types.Transaction, error) {
+	client := s.backend.Client()
+	nonce, err := client.PendingNonceAt(ctx, s.from)
+	if err != nil {
+		return nil, err
+	}
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	tip, err := client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := types.SignNewTx(s.key, types.LatestSignerForChainID(s.chainID), &types.DynamicFeeTx{
+		ChainID:   s.chainID,
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: new(big.Int).Add(new(big.Int).Mul(head.BaseFee, big.NewInt(2)), tip),
+		Gas:       simGasLimit,
+		To:        to,
+		Data:      data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		return nil, err
+	}
+	s.backend.Commit()
+	// Mined transactions are indexed in the background, their receipts are not found until then
+	for {
+		_, err := client.TransactionReceipt(ctx, tx.Hash())
+		if err == nil {
+			return tx, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// deploy deploys the contract built from contracts/src/<name>.sol with the ABI encoded constructor arguments
+func (s *simEVM) deploy(t *testing.T, name string, args ...[]byte) common.Address {
+	data := creationCode(t, name)
+	for _, arg := range args {
+		data = append(data, arg...)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tx, err := s.send(context.Background(), nil, data)
+	require.NoError(t, err)
+	r, err := s.backend.Client().TransactionReceipt(context.Background(), tx.Hash())
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, r.Status, "deploying %s", name)
+	return r.ContractAddress
+}
+
+// deployLightClient deploys ZKLightClient trusting the committee of v, with the verifier of the mode of v
+func (s *simEVM) deployLightClient(t *testing.T, v proofVector) common.Address {
+	verifier := "VerifierExpandInCircuit"
+	var expandInContract byte
+	if v.ExpandInContract {
+		verifier, expandInContract = "Verifier", 1
+	}
+	word := func(b []byte) []byte { return common.LeftPadBytes(b, 32) }
+	return s.deploy(t, "ZKLightClient",
+		word(s.deploy(t, verifier).Bytes()),
+		word(s.deploy(t, "BLS12381").Bytes()),
+		word([]byte{expandInContract}),
+		word(v.CommitteeRoot),
+		word(new(big.Int).SetUint64(v.TotalStake).Bytes()),
+	)
+}
+
+// vectorSource serves the checkpoint of a proof vector as the latest one
+type vectorSource struct {
+	checkpoint *sui.CertifiedCheckpointSummary
+}
+
+func (s *vectorSource) LatestCheckpoint(context.Context) (uint64, error) {
+	return s.checkpoint.Summary.SequenceNumber, nil
+}
+
+func (s *vectorSource) Checkpoint(_ context.Context, seq uint64) (*sui.CertifiedCheckpointSummary, error) {
+	if seq != s.checkpoint.Summary.SequenceNumber {
+		return nil, errors.New("unknown checkpoint")
+	}
+	return s.checkpoint, nil
+}
+
+func (s *vectorSource) Committee(_ context.Context, epoch uint64) (*sui.Committee, error) {
+	return &sui.Committee{Epoch: epoch}, nil
+}
+
+// vectorProve returns the proof of v, after checking that the relayer read the committee root of v from the contract
+func vectorProve(v proofVector) prover.ProveFunc {
+	return func(_ context.Context, _ *sui.Committee, checkpoint *sui.CertifiedCheckpointSummary, committeeRoot *big.Int) (*prover.Proof, error) {
+		if committeeRoot.Cmp(new(big.Int).SetBytes(v.CommitteeRoot)) != 0 {
+			return nil, errors.New("unexpected committee root")
+		}
+		p, commitments, commitmentPok, err := utils.UnpackSolidityProof(v.Proof)
+		if err != nil {
+			return nil, err
+		}
+		return &prover.Proof{Proof: p, Commitments: commitments, CommitmentPok: commitmentPok}, nil
+	}
+}
+
+// TestRelayerEVM relays real proofs to ZKLightClient deployed on go-ethereum's EVM
+func TestRelayerEVM(t *testing.T) {
+	forgeBuild(t)
+	for _, v := range readProofVectors(t) {
+		t.Run(v.Name, func(t *testing.T) {
+			evm, srv := newSimEVM(t)
+			contract := evm.deployLightClient(t, v)
+			summary, err := sui.ParseSigningMessage(v.CheckpointIntent)
+			require.NoError(t, err)
+			source := &vectorSource{checkpoint: &sui.CertifiedCheckpointSummary{Summary: *summary}}
+			store := &FileStore{Path: filepath.Join(t.TempDir(), "progress.json")}
+			prove := vectorProve(v)
+			r := New(Config{
+				Source: source,
+				Prove: func(ctx context.Context, c *sui.Committee, cp *sui.CertifiedCheckpointSummary, root *big.Int) (*prover.Proof, error) {
+					return prove(ctx, c, cp, root)
+				},
+				Eth:      &EthClient{URL: srv.URL, ReceiptPollInterval: 1},
+				Store:    store,
+				Contract: contract.Hex(),
+				From:     evm.from.Hex(),
+			})
+
+			// A proof not verifying is reverted by the contract and leaves the progress untouched
+			prove = func(ctx context.Context, c *sui.Committee, cp *sui.CertifiedCheckpointSummary, root *big.Int) (*prover.Proof, error) {
+				proof, err := vectorProve(v)(ctx, c, cp, root)
+				if err != nil {
+					return nil, err
+				}
+				proof.Proof[0] = new(big.Int).Add(proof.Proof[0], big.NewInt(1))
+				return proof, nil
+			}
+			_, err = r.Step(context.Background())
+			require.ErrorIs(t, err, ErrTxReverted)
+			p, err := store.Load()
+			require.NoError(t, err)
+			require.Nil(t, p.LastSequenceNumber)
+
+			prove = vectorProve(v)
+			relayed, err := r.Step(context.Background())
+			require.NoError(t, err)
+			require.True(t, relayed)
+			p, err = store.Load()
+			require.NoError(t, err)
+			require.Equal(t, summary.SequenceNumber, *p.LastSequenceNumber)
+			receipt, err := evm.backend.Client().TransactionReceipt(context.Background(), common.HexToHash(p.LastTx))
+			require.NoError(t, err)
+			require.Len(t, receipt.Logs, 1, "Verified")
+			require.Equal(t, contract, receipt.Logs[0].Address)
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Package relayer moves certified Sui checkpoints to ZKLightClient: it follows a checkpoint source, proves the latest
+// checkpoint with SigVerifyCircuit and submits it with updateCheckpoint.
+package relayer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/patrickmao1/zuika/circuits"
+	"github.com/patrickmao1/zuika/prover"
+	"github.com/patrickmao1/zuika/sui"
+	"github.com/patrickmao1/zuika/utils"
+	"log"
+	"math/big"
+	"time"
+)
+
+// Source provides certified checkpoints and the committees that signed them
+type Source interface {
+	LatestCheckpoint(ctx context.Context) (uint64, error)
+	Checkpoint(ctx context.Context, seq uint64) (*sui.CertifiedCheckpointSummary, error)
+	Committee(ctx context.Context, epoch uint64) (*sui.Committee, error)
+}
+
+type Config struct {
+	Source Source
+	Prove  prover.ProveFunc
+	Eth    *EthClient
+	Store  Store
+	// Address of ZKLightClient
+	Contract string
+	// Account the transactions are sent from
+	From string
+	// How often the source is polled for a new checkpoint, ten seconds if zero
+	PollInterval time.Duration
+}
+
+type Relayer struct {
+	cfg Config
+}
+
+func New(cfg Config) *Relayer {
+	if cfg.PollInterval == 0 {
+		cfg.PollInterval = 10 * time.Second
+	}
+	return &Relayer{cfg: cfg}
+}
+
+// Run relays checkpoints until ctx is done. Errors are logged and the step is retried after the poll interval, except
+// circuits.ErrCommitteeRootMismatch which Run returns: the contract only accepts checkpoints signed by the committee it
+// trusts, so once the source has moved to another epoch no step can succeed.
+func (r *Relayer) Run(ctx context.Context) error {
+	for {
+		relayed, err := r.Step(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if errors.Is(err, circuits.ErrCommitteeRootMismatch) {
+				return err
+			}
+			log.Printf("relay: %v", err)
+		}
+		if relayed {
+			// Proving takes longer than the poll interval, a newer checkpoint is already there
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.cfg.PollInterval):
+		}
+	}
+}
+
+// Step settles the pending transaction of a previous step, if any, then relays the latest checkpoint if it is newer
+// than the last relayed one. Checkpoints in between are skipped.
+func (r *Relayer) Step(ctx context.Context) (relayed bool, err error) {
+	p, err := r.cfg.Store.Load()
+	if err != nil {
+		return false, err
+	}
+	if p.Pending != nil {
+		err := r.settle(ctx, p)
+		switch {
+		case errors.Is(err, ErrTxDropped):
+			// Relayed again below
+			log.Printf("relay: %v", err)
+		case err != nil:
+			return false, err
+		}
+	}
+
+	latest, err := r.cfg.Source.LatestCheckpoint(ctx)
+	if err != nil {
+		return false, fmt.Errorf("latest checkpoint: %w", err)
+	}
+	if p.LastSequenceNumber != nil && latest <= *p.LastSequenceNumber {
+		return false, nil
+	}
+	checkpoint, err := r.cfg.Source.Checkpoint(ctx, latest)
+	if err != nil {
+		return false, fmt.Errorf("checkpoint %d: %w", latest, err)
+	}
+	committee, err := r.cfg.Source.Committee(ctx, checkpoint.Summary.Epoch)
+	if err != nil {
+		return false, fmt.Errorf("committee of epoch %d: %w", checkpoint.Summary.Epoch, err)
+	}
+
+	committeeRoot, err := r.committeeRoot(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	start := time.Now()
+	proof, err := r.cfg.Prove(ctx, committee, checkpoint, committeeRoot)
+	if err != nil {
+		return false, fmt.Errorf("proving checkpoint %d: %w", latest, err)
+	}
+	log.Printf("proved checkpoint %d in %s", latest, time.Since(start))
+
+	zkProof := utils.PackProofForSolidity(proof.Proof, proof.Commitments, proof.CommitmentPok)
+	data := utils.UpdateCheckpointCalldata(checkpoint.Summary.SigningMessage(), zkProof)
+	hash, err := r.cfg.Eth.SendTransaction(ctx, r.cfg.From, r.cfg.Contract, data)
+	if err != nil {
+		return false, fmt.Errorf("sending checkpoint %d: %w", latest, err)
+	}
+	log.Printf("sent checkpoint %d in %s", latest, hash)
+	p.Pending = &PendingTx{SequenceNumber: latest, Hash: hash}
+	if err := r.save(p); err != nil {
+		return false, err
+	}
+	if err := r.settle(ctx, p); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// committeeRoot reads the CommitteeRoot the contract currently verifies proofs against
+func (r *Relayer) committeeRoot(ctx context.Context) (*big.Int, error) {
+	out, err := r.cfg.Eth.Call(ctx, r.cfg.Contract, utils.CurrentCommitteeRootSelector[:])
+	if err != nil {
+		return nil, fmt.Errorf("committee root: %w", err)
+	}
+	if len(out) != 32 {
+		return nil, fmt.Errorf("committee root: unexpected output length %d", len(out))
+	}
+	return new(big.Int).SetBytes(out), nil
+}
+
+// settle waits for the pending transaction and records its outcome
+func (r *Relayer) settle(ctx context.Context, p *Progress) error {
+	pending := p.Pending
+	err := r.cfg.Eth.WaitMined(ctx, pending.Hash)
+	switch {
+	case err == nil:
+		seq := pending.SequenceNumber
+		p.LastSequenceNumber, p.LastTx, p.Pending = &seq, pending.Hash, nil
+		log.Printf("checkpoint %d accepted in %s", seq, pending.Hash)
+	case errors.Is(err, ErrTxReverted), errors.Is(err, ErrTxDropped):
+		// Dropped so that the next step relays the latest checkpoint again
+		p.Pending = nil
+	default:
+		return err
+	}
+	if saveErr := r.save(p); saveErr != nil {
+		return saveErr
+	}
+	if err != nil {
+		return fmt.Errorf("checkpoint %d: %w", pending.SequenceNumber, err)
+	}
+	return nil
+}
+
+func (r *Relayer) save(p *Progress) error {
+	p.Updated = time.Now().UTC()
+	return r.cfg.Store.Save(p)
+}
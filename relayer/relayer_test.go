@@ -0,0 +1,373 @@
+package relayer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/patrickmao1/zuika/circuits"
+	"github.com/patrickmao1/zuika/prover"
+	"github.com/patrickmao1/zuika/sui"
+	"github.com/patrickmao1/zuika/utils"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testEpoch is the epoch of the committee trusted by the contract deployed on mockEVM
+const testEpoch = 7
+
+// memSource is a Sui checkpoint source serving generated checkpoints up to latest, signed in testEpoch unless epoch
+// is set
+type memSource struct {
+	mu     sync.Mutex
+	latest uint64
+	epoch  uint64
+}
+
+func (s *memSource) setLatest(seq uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latest = seq
+}
+
+func (s *memSource) setEpoch(epoch uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.epoch = epoch
+}
+
+func (s *memSource) LatestCheckpoint(context.Context) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.latest, nil
+}
+
+func (s *memSource) Checkpoint(_ context.Context, seq uint64) (*sui.CertifiedCheckpointSummary, error) {
+	s.mu.Lock()
+	epoch := s.epoch
+	s.mu.Unlock()
+	if epoch == 0 {
+		epoch = testEpoch
+	}
+	prev := sui.Digest{byte(seq - 1)}
+	return &sui.CertifiedCheckpointSummary{
+		Summary: sui.CheckpointSummary{
+			Epoch:          epoch,
+			SequenceNumber: seq,
+			ContentDigest:  sui.Digest{byte(seq)},
+			PreviousDigest: &prev,
+			TimestampMs:    1700000000000 + seq,
+		},
+		AuthSignature: sui.AuthorityQuorumSignInfo{Epoch: epoch},
+	}, nil
+}
+
+func (s *memSource) Committee(_ context.Context, epoch uint64) (*sui.Committee, error) {
+	return &sui.Committee{Epoch: epoch, Members: []sui.CommitteeMember{{Stake: 1}}}, nil
+}
+
+// testCommitteeRoot is the currentCommitteeRoot of the contract deployed on mockEVM
+var testCommitteeRoot = big.NewInt(0x1234)
+
+// fakeProve "proves" a checkpoint by putting its sequence number in the first proof word, which mockEVM checks. Like
+// the real prover, it refuses to prove for a committee whose root is not the contract's, here any but testEpoch's.
+func fakeProve(_ context.Context, committee *sui.Committee, checkpoint *sui.CertifiedCheckpointSummary, committeeRoot *big.Int) (*prover.Proof, error) {
+	if committeeRoot.Cmp(testCommitteeRoot) != 0 {
+		return nil, fmt.Errorf("committee root %x", committeeRoot)
+	}
+	if committee != nil && committee.Epoch != testEpoch {
+		return nil, fmt.Errorf("%w: committee of epoch %d", circuits.ErrCommitteeRootMismatch, committee.Epoch)
+	}
+	p := &prover.Proof{}
+	for i := range p.Proof {
+		p.Proof[i] = big.NewInt(int64(i))
+	}
+	p.Proof[0] = new(big.Int).SetUint64(checkpoint.Summary.SequenceNumber)
+	p.Commitments = [2]*big.Int{big.NewInt(8), big.NewInt(9)}
+	p.CommitmentPok = [2]*big.Int{big.NewInt(10), big.NewInt(11)}
+	return p, nil
+}
+
+// mockEVM is a JSON-RPC endpoint standing in for a node with ZKLightClient deployed at contract. It is a stub: it
+// neither runs the contract nor verifies proofs, it accepts updateCheckpoint transactions whose calldata decodes and
+// whose proof passes fakeProve's check, and mines them on the second receipt poll. It only covers the relayer's
+// transaction handling, TestRelayerEVM runs the contract.
+type mockEVM struct {
+	contract string
+
+	mu sync.Mutex
+	// Transactions sent while drop is set are never mined
+	drop     bool
+	sent     int
+	accepted []uint64
+	status   map[string]string
+	polls    map[string]int
+}
+
+func newMockEVM(contract string) (*mockEVM, *httptest.Server) {
+	m := &mockEVM{contract: contract, status: map[string]string{}, polls: map[string]int{}}
+	return m, httptest.NewServer(m)
+}
+
+func (m *mockEVM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	serveJSONRPC(w, r, func(method string, params []json.RawMessage) (any, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		return m.handle(method, params)
+	})
+}
+
+// serveJSONRPC answers a JSON-RPC request with handle. Malformed requests and handle errors are answered with a
+// JSON-RPC error, which fails the relayer's call and so the test.
+func serveJSONRPC(w http.ResponseWriter, r *http.Request, handle func(method string, params []json.RawMessage) (any, error)) {
+	var req struct {
+		ID     uint64            `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	result, err := handle(req.Method, req.Params)
+	if err != nil {
+		var rpcErr *rpcError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &rpcError{Code: -32602, Message: err.Error()}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"id": req.ID, "error": rpcErr})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]any{"id": req.ID, "result": result})
+}
+
+func (m *mockEVM) handle(method string, params []json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, errors.New("missing params")
+	}
+	switch method {
+	case "eth_sendTransaction":
+		var tx sendTxArgs
+		if err := json.Unmarshal(params[0], &tx); err != nil {
+			return nil, err
+		}
+		if tx.To != m.contract {
+			return nil, fmt.Errorf("transaction to %s, contract is %s", tx.To, m.contract)
+		}
+		data, err := parseHexBytes(tx.Data)
+		if err != nil {
+			return nil, err
+		}
+		m.sent++
+		hash := fmt.Sprintf("0x%064x", m.sent)
+		if !m.drop {
+			if m.status[hash], err = m.execute(data); err != nil {
+				return nil, err
+			}
+		}
+		return hash, nil
+	case "eth_call":
+		var call callArgs
+		if err := json.Unmarshal(params[0], &call); err != nil {
+			return nil, err
+		}
+		if call.To != m.contract || call.Data != hexBytes(utils.CurrentCommitteeRootSelector[:]) {
+			return nil, fmt.Errorf("unexpected call %+v", call)
+		}
+		return hexBytes(testCommitteeRoot.FillBytes(make([]byte, 32))), nil
+	case "eth_getTransactionReceipt":
+		var hash string
+		if err := json.Unmarshal(params[0], &hash); err != nil {
+			return nil, err
+		}
+		m.polls[hash]++
+		if status, ok := m.status[hash]; ok && m.polls[hash] > 1 {
+			return receipt{Status: status, BlockNumber: "0x1"}, nil
+		}
+		return nil, nil
+	}
+	return nil, &rpcError{Code: -32601, Message: "not found"}
+}
+
+// execute runs updateCheckpoint and returns the receipt status. Calldata that does not decode is an error of the
+// relayer rather than a revert.
+func (m *mockEVM) execute(data []byte) (string, error) {
+	intent, zkProof, err := utils.DecodeUpdateCheckpointCalldata(data)
+	if err != nil {
+		return "", err
+	}
+	summary, err := sui.ParseSigningMessage(intent)
+	if err != nil {
+		return "", err
+	}
+	p, _, _, err := utils.UnpackSolidityProof(zkProof)
+	if err != nil {
+		return "", err
+	}
+	if p[0].Uint64() != summary.SequenceNumber {
+		return "0x0", nil
+	}
+	m.accepted = append(m.accepted, summary.SequenceNumber)
+	return "0x1", nil
+}
+
+func TestRelayer(t *testing.T) {
+	const contract = "0x00000000000000000000000000000000000000aa"
+	evm, srv := newMockEVM(contract)
+	defer srv.Close()
+	source := &memSource{latest: 100}
+	store := &FileStore{Path: filepath.Join(t.TempDir(), "progress.json")}
+	prove := fakeProve
+	r := New(Config{
+		Source: source,
+		Prove: func(ctx context.Context, c *sui.Committee, cp *sui.CertifiedCheckpointSummary, root *big.Int) (*prover.Proof, error) {
+			return prove(ctx, c, cp, root)
+		},
+		Eth:      &EthClient{URL: srv.URL, ReceiptPollInterval: 1},
+		Store:    store,
+		Contract: contract,
+		From:     "0x00000000000000000000000000000000000000bb",
+	})
+	ctx := context.Background()
+
+	relayed, err := r.Step(ctx)
+	require.NoError(t, err)
+	require.True(t, relayed)
+	relayed, err = r.Step(ctx)
+	require.NoError(t, err)
+	require.False(t, relayed)
+
+	// Checkpoints in between are skipped
+	source.setLatest(105)
+	relayed, err = r.Step(ctx)
+	require.NoError(t, err)
+	require.True(t, relayed)
+	require.Equal(t, []uint64{100, 105}, evm.accepted)
+	p, err := store.Load()
+	require.NoError(t, err)
+	require.Equal(t, uint64(105), *p.LastSequenceNumber)
+	require.Nil(t, p.Pending)
+
+	// A rejected proof leaves the progress untouched
+	prove = func(ctx context.Context, c *sui.Committee, cp *sui.CertifiedCheckpointSummary, root *big.Int) (*prover.Proof, error) {
+		proof, _ := fakeProve(ctx, c, cp, root)
+		proof.Proof[0] = big.NewInt(0)
+		return proof, nil
+	}
+	source.setLatest(110)
+	_, err = r.Step(ctx)
+	require.ErrorIs(t, err, ErrTxReverted)
+	p, err = store.Load()
+	require.NoError(t, err)
+	require.Equal(t, uint64(105), *p.LastSequenceNumber)
+	require.Nil(t, p.Pending)
+	require.Equal(t, 3, evm.sent)
+}
+
+func TestRelayerPendingAfterRestart(t *testing.T) {
+	const contract = "0x00000000000000000000000000000000000000aa"
+	evm, srv := newMockEVM(contract)
+	defer srv.Close()
+	eth := &EthClient{URL: srv.URL, ReceiptPollInterval: 1}
+	source := &memSource{latest: 200}
+	store := &FileStore{Path: filepath.Join(t.TempDir(), "progress.json")}
+
+	// The previous process sent checkpoint 200 and stopped before it was mined
+	proof, err := fakeProve(context.Background(), nil, &sui.CertifiedCheckpointSummary{
+		Summary: sui.CheckpointSummary{SequenceNumber: 200},
+	}, testCommitteeRoot)
+	require.NoError(t, err)
+	checkpoint, err := source.Checkpoint(context.Background(), 200)
+	require.NoError(t, err)
+	zkProof := utils.PackProofForSolidity(proof.Proof, proof.Commitments, proof.CommitmentPok)
+	hash, err := eth.SendTransaction(context.Background(), "0xbb", contract,
+		utils.UpdateCheckpointCalldata(checkpoint.Summary.SigningMessage(), zkProof))
+	require.NoError(t, err)
+	require.NoError(t, store.Save(&Progress{Pending: &PendingTx{SequenceNumber: 200, Hash: hash}}))
+
+	r := New(Config{Source: source, Prove: fakeProve, Eth: eth, Store: store, Contract: contract, From: "0xbb"})
+	relayed, err := r.Step(context.Background())
+	require.NoError(t, err)
+	require.False(t, relayed)
+	require.Equal(t, 1, evm.sent)
+	p, err := store.Load()
+	require.NoError(t, err)
+	require.Equal(t, uint64(200), *p.LastSequenceNumber)
+	require.Equal(t, hash, p.LastTx)
+}
+
+func TestRelayerDropped(t *testing.T) {
+	const contract = "0x00000000000000000000000000000000000000aa"
+	evm, srv := newMockEVM(contract)
+	defer srv.Close()
+	source := &memSource{latest: 300}
+	store := &FileStore{Path: filepath.Join(t.TempDir(), "progress.json")}
+	eth := &EthClient{URL: srv.URL, ReceiptPollInterval: 1, ReceiptTimeout: 20 * time.Millisecond}
+	r := New(Config{Source: source, Prove: fakeProve, Eth: eth, Store: store, Contract: contract, From: "0xbb"})
+
+	evm.mu.Lock()
+	evm.drop = true
+	evm.mu.Unlock()
+	_, err := r.Step(context.Background())
+	require.ErrorIs(t, err, ErrTxDropped)
+	p, err := store.Load()
+	require.NoError(t, err)
+	require.Nil(t, p.LastSequenceNumber)
+	require.Nil(t, p.Pending)
+
+	// The checkpoint is relayed again
+	evm.mu.Lock()
+	evm.drop = false
+	evm.mu.Unlock()
+	relayed, err := r.Step(context.Background())
+	require.NoError(t, err)
+	require.True(t, relayed)
+	require.Equal(t, []uint64{300}, evm.accepted)
+
+	// A transaction left pending by a previous process and since dropped is relayed again in the same step
+	source.setLatest(301)
+	p, err = store.Load()
+	require.NoError(t, err)
+	p.Pending = &PendingTx{SequenceNumber: 301, Hash: fmt.Sprintf("0x%064x", 0xdead)}
+	require.NoError(t, store.Save(p))
+	relayed, err = r.Step(context.Background())
+	require.NoError(t, err)
+	require.True(t, relayed)
+	require.Equal(t, []uint64{300, 301}, evm.accepted)
+	p, err = store.Load()
+	require.NoError(t, err)
+	require.Equal(t, uint64(301), *p.LastSequenceNumber)
+}
+
+func TestRelayerCommitteeChanged(t *testing.T) {
+	const contract = "0x00000000000000000000000000000000000000aa"
+	evm, srv := newMockEVM(contract)
+	defer srv.Close()
+	source := &memSource{latest: 400}
+	store := &FileStore{Path: filepath.Join(t.TempDir(), "progress.json")}
+	eth := &EthClient{URL: srv.URL, ReceiptPollInterval: 1}
+	r := New(Config{Source: source, Prove: fakeProve, Eth: eth, Store: store, Contract: contract, From: "0xbb",
+		PollInterval: time.Millisecond})
+
+	relayed, err := r.Step(context.Background())
+	require.NoError(t, err)
+	require.True(t, relayed)
+
+	// Checkpoints of the next epoch cannot be proven against the contract's committee, Run stops instead of
+	// proving them again at every poll
+	source.setLatest(401)
+	source.setEpoch(testEpoch + 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err = r.Run(ctx)
+	require.ErrorIs(t, err, circuits.ErrCommitteeRootMismatch)
+	require.NoError(t, ctx.Err())
+	require.Equal(t, []uint64{400}, evm.accepted)
+	require.Equal(t, 1, evm.sent)
+}
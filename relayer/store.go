@@ -0,0 +1,61 @@
+package relayer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Progress is the relayer state persisted across restarts
+type Progress struct {
+	// Sequence number of the last checkpoint accepted by the contract, nil before the first one
+	LastSequenceNumber *uint64 `json:"lastSequenceNumber,omitempty"`
+	LastTx             string  `json:"lastTx,omitempty"`
+	// Transaction sent but not yet known to be mined. It is waited for after a restart instead of being resent.
+	Pending *PendingTx `json:"pending,omitempty"`
+	Updated time.Time  `json:"updated"`
+}
+
+type PendingTx struct {
+	SequenceNumber uint64 `json:"sequenceNumber"`
+	Hash           string `json:"hash"`
+}
+
+type Store interface {
+	Load() (*Progress, error)
+	Save(*Progress) error
+}
+
+// FileStore keeps the progress in a JSON file
+type FileStore struct {
+	Path string
+}
+
+func (s *FileStore) Load() (*Progress, error) {
+	b, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Progress{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	p := new(Progress)
+	if err := json.Unmarshal(b, p); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.Path, err)
+	}
+	return p, nil
+}
+
+func (s *FileStore) Save(p *Progress) error {
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path)
+}
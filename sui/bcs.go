@@ -0,0 +1,158 @@
+package sui
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Minimal BCS (https://github.com/diem/bcs) encoding for the types of this package
+
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) u8(v uint8) {
+	e.buf = append(e.buf, v)
+}
+
+func (e *encoder) u64(v uint64) {
+	e.buf = binary.LittleEndian.AppendUint64(e.buf, v)
+}
+
+func (e *encoder) uleb128(v uint64) {
+	for v >= 0x80 {
+		e.buf = append(e.buf, byte(v)|0x80)
+		v >>= 7
+	}
+	e.buf = append(e.buf, byte(v))
+}
+
+// bytes writes a length prefixed byte vector
+func (e *encoder) bytes(b []byte) {
+	e.uleb128(uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) option(some bool) {
+	if some {
+		e.u8(1)
+	} else {
+		e.u8(0)
+	}
+}
+
+var errUnexpectedEOF = errors.New("bcs: unexpected end of input")
+
+// decoder reads BCS values from b. The first error is sticky, all subsequent reads return zero values.
+type decoder struct {
+	b   []byte
+	off int
+	err error
+}
+
+func (d *decoder) fail(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+func (d *decoder) read(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || len(d.b)-d.off < n {
+		d.fail(errUnexpectedEOF)
+		return nil
+	}
+	b := d.b[d.off : d.off+n]
+	d.off += n
+	return b
+}
+
+func (d *decoder) u8() uint8 {
+	b := d.read(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (d *decoder) u64() uint64 {
+	b := d.read(8)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(b)
+}
+
+func (d *decoder) uleb128() uint64 {
+	var v uint64
+	for shift := 0; shift < 64; shift += 7 {
+		b := d.u8()
+		if d.err != nil {
+			return 0
+		}
+		v |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			if b == 0 && shift > 0 {
+				d.fail(errors.New("bcs: non-canonical uleb128"))
+				return 0
+			}
+			return v
+		}
+	}
+	d.fail(errors.New("bcs: uleb128 overflow"))
+	return 0
+}
+
+// length reads a sequence length, bounded by the remaining input to avoid huge allocations on malformed input
+func (d *decoder) length() int {
+	n := d.uleb128()
+	if d.err == nil && n > uint64(len(d.b)-d.off) {
+		d.fail(fmt.Errorf("bcs: sequence length %d exceeds remaining input", n))
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) bytes() []byte {
+	b := d.read(d.length())
+	if len(b) == 0 {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
+
+// fixedBytes reads a length prefixed byte vector that must be exactly len(dst) long
+func (d *decoder) fixedBytes(dst []byte) {
+	n := d.length()
+	if d.err == nil && n != len(dst) {
+		d.fail(fmt.Errorf("bcs: expected %d bytes, got %d", len(dst), n))
+		return
+	}
+	copy(dst, d.read(n))
+}
+
+func (d *decoder) option() bool {
+	switch tag := d.u8(); tag {
+	case 0:
+		return false
+	case 1:
+		return true
+	default:
+		d.fail(fmt.Errorf("bcs: invalid option tag %d", tag))
+		return false
+	}
+}
+
+// finish returns the first error, or an error if not all input was consumed
+func (d *decoder) finish() error {
+	if d.err != nil {
+		return d.err
+	}
+	if d.off != len(d.b) {
+		return fmt.Errorf("bcs: %d trailing bytes", len(d.b)-d.off)
+	}
+	return nil
+}
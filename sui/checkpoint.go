@@ -0,0 +1,225 @@
+package sui
+
+import (
+	"fmt"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+)
+
+// Intent of checkpoint summaries: scope CheckpointSummary, version V0, app id Sui
+var CheckpointSummaryIntent = [3]byte{2, 0, 0}
+
+type GasCostSummary struct {
+	ComputationCost         uint64
+	StorageCost             uint64
+	StorageRebate           uint64
+	NonRefundableStorageFee uint64
+}
+
+// CheckpointCommitment only has the ECMHLiveObjectSetDigest variant
+type CheckpointCommitment struct {
+	ECMHLiveObjectSetDigest Digest
+}
+
+type EndOfEpochData struct {
+	NextEpochCommittee       []CommitteeMember
+	NextEpochProtocolVersion uint64
+	EpochCommitments         []CheckpointCommitment
+}
+
+type CheckpointSummary struct {
+	Epoch                      uint64
+	SequenceNumber             uint64
+	NetworkTotalTransactions   uint64
+	ContentDigest              Digest
+	PreviousDigest             *Digest
+	EpochRollingGasCostSummary GasCostSummary
+	TimestampMs                uint64
+	CheckpointCommitments      []CheckpointCommitment
+	EndOfEpochData             *EndOfEpochData
+	VersionSpecificData        []byte
+}
+
+// AuthorityQuorumSignInfo is the aggregated signature of a quorum of the committee of Epoch. SignersMap is the
+// serialized roaring bitmap of the signers' indices in the committee.
+type AuthorityQuorumSignInfo struct {
+	Epoch      uint64
+	Signature  [48]byte
+	SignersMap []byte
+}
+
+type CertifiedCheckpointSummary struct {
+	Summary       CheckpointSummary
+	AuthSignature AuthorityQuorumSignInfo
+}
+
+func (s *CheckpointSummary) MarshalBCS() []byte {
+	e := &encoder{}
+	s.encode(e)
+	return e.buf
+}
+
+func (s *CheckpointSummary) UnmarshalBCS(b []byte) error {
+	d := &decoder{b: b}
+	s.decode(d)
+	return d.finish()
+}
+
+// Digest is the CheckpointDigest, the value of the next checkpoint's PreviousDigest
+func (s *CheckpointSummary) Digest() Digest {
+	return digestOf("CheckpointSummary", s.MarshalBCS())
+}
+
+// SigningMessage is the message signed by the committee: intent || bcs(CheckpointSummary) || epoch. It is the
+// CheckpointSummary input of SigVerifyCircuit.
+func (s *CheckpointSummary) SigningMessage() []byte {
+	e := &encoder{buf: append([]byte{}, CheckpointSummaryIntent[:]...)}
+	s.encode(e)
+	e.u64(s.Epoch)
+	return e.buf
+}
+
+// ParseSigningMessage is the inverse of SigningMessage
+func ParseSigningMessage(msg []byte) (*CheckpointSummary, error) {
+	if len(msg) < len(CheckpointSummaryIntent)+8 {
+		return nil, fmt.Errorf("signing message too short: %d", len(msg))
+	}
+	if [3]byte(msg[:3]) != CheckpointSummaryIntent {
+		return nil, fmt.Errorf("unexpected intent %x", msg[:3])
+	}
+	s := new(CheckpointSummary)
+	d := &decoder{b: msg[3 : len(msg)-8]}
+	s.decode(d)
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+	tail := &decoder{b: msg[len(msg)-8:]}
+	if epoch := tail.u64(); epoch != s.Epoch {
+		return nil, fmt.Errorf("signing message epoch %d != summary epoch %d", epoch, s.Epoch)
+	}
+	return s, nil
+}
+
+func (s *CheckpointSummary) encode(e *encoder) {
+	e.u64(s.Epoch)
+	e.u64(s.SequenceNumber)
+	e.u64(s.NetworkTotalTransactions)
+	encodeDigest(e, &s.ContentDigest)
+	e.option(s.PreviousDigest != nil)
+	if s.PreviousDigest != nil {
+		encodeDigest(e, s.PreviousDigest)
+	}
+	g := &s.EpochRollingGasCostSummary
+	e.u64(g.ComputationCost)
+	e.u64(g.StorageCost)
+	e.u64(g.StorageRebate)
+	e.u64(g.NonRefundableStorageFee)
+	e.u64(s.TimestampMs)
+	encodeCommitments(e, s.CheckpointCommitments)
+	e.option(s.EndOfEpochData != nil)
+	if eoe := s.EndOfEpochData; eoe != nil {
+		e.uleb128(uint64(len(eoe.NextEpochCommittee)))
+		for i := range eoe.NextEpochCommittee {
+			eoe.NextEpochCommittee[i].encode(e)
+		}
+		e.u64(eoe.NextEpochProtocolVersion)
+		encodeCommitments(e, eoe.EpochCommitments)
+	}
+	e.bytes(s.VersionSpecificData)
+}
+
+func (s *CheckpointSummary) decode(d *decoder) {
+	s.Epoch = d.u64()
+	s.SequenceNumber = d.u64()
+	s.NetworkTotalTransactions = d.u64()
+	decodeDigest(d, &s.ContentDigest)
+	s.PreviousDigest = nil
+	if d.option() {
+		s.PreviousDigest = new(Digest)
+		decodeDigest(d, s.PreviousDigest)
+	}
+	g := &s.EpochRollingGasCostSummary
+	g.ComputationCost = d.u64()
+	g.StorageCost = d.u64()
+	g.StorageRebate = d.u64()
+	g.NonRefundableStorageFee = d.u64()
+	s.TimestampMs = d.u64()
+	s.CheckpointCommitments = decodeCommitments(d)
+	s.EndOfEpochData = nil
+	if d.option() {
+		eoe := new(EndOfEpochData)
+		n := d.length()
+		for i := 0; i < n && d.err == nil; i++ {
+			var m CommitteeMember
+			m.decode(d)
+			eoe.NextEpochCommittee = append(eoe.NextEpochCommittee, m)
+		}
+		eoe.NextEpochProtocolVersion = d.u64()
+		eoe.EpochCommitments = decodeCommitments(d)
+		s.EndOfEpochData = eoe
+	}
+	s.VersionSpecificData = d.bytes()
+}
+
+func (c *CertifiedCheckpointSummary) MarshalBCS() []byte {
+	e := &encoder{}
+	c.Summary.encode(e)
+	c.AuthSignature.encode(e)
+	return e.buf
+}
+
+func (c *CertifiedCheckpointSummary) UnmarshalBCS(b []byte) error {
+	d := &decoder{b: b}
+	c.Summary.decode(d)
+	c.AuthSignature.decode(d)
+	return d.finish()
+}
+
+func (a *AuthorityQuorumSignInfo) encode(e *encoder) {
+	e.u64(a.Epoch)
+	e.bytes(a.Signature[:])
+	e.bytes(a.SignersMap)
+}
+
+func (a *AuthorityQuorumSignInfo) decode(d *decoder) {
+	a.Epoch = d.u64()
+	d.fixedBytes(a.Signature[:])
+	a.SignersMap = d.bytes()
+}
+
+// G1 decodes the compressed aggregated signature
+func (a *AuthorityQuorumSignInfo) G1() (bls12381.G1Affine, error) {
+	var sig bls12381.G1Affine
+	_, err := sig.SetBytes(a.Signature[:])
+	return sig, err
+}
+
+func encodeDigest(e *encoder, d *Digest) {
+	e.bytes(d[:])
+}
+
+func decodeDigest(d *decoder, dst *Digest) {
+	d.fixedBytes(dst[:])
+}
+
+func encodeCommitments(e *encoder, cs []CheckpointCommitment) {
+	e.uleb128(uint64(len(cs)))
+	for i := range cs {
+		e.u8(0)
+		encodeDigest(e, &cs[i].ECMHLiveObjectSetDigest)
+	}
+}
+
+func decodeCommitments(d *decoder) []CheckpointCommitment {
+	n := d.length()
+	var cs []CheckpointCommitment
+	for i := 0; i < n && d.err == nil; i++ {
+		if variant := d.uleb128(); variant != 0 {
+			d.fail(fmt.Errorf("bcs: unknown CheckpointCommitment variant %d", variant))
+			return nil
+		}
+		var c CheckpointCommitment
+		decodeDigest(d, &c.ECMHLiveObjectSetDigest)
+		cs = append(cs, c)
+	}
+	return cs
+}
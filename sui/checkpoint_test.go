@@ -0,0 +1,108 @@
+package sui
+
+import (
+	"encoding/hex"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+	"testing"
+)
+
+// signing message of checkpoint 134973309
+const testSigningMessage = "020000e0020000000000007d870b08000000007c491ecb0000000020e767c5b2706f4d810d28664f9b5b0731d085156a3afcb72ddf67373cdf99057f012067d6d26500b1403a1a464cbd64d0aa61e02eace13f2c267f970f70bd3ed4fd38000000000000000000000000000000000000000000000000000000000000000038aed544960100000000020000e002000000000000"
+
+func TestParseSigningMessage(t *testing.T) {
+	msg, err := hex.DecodeString(testSigningMessage)
+	require.NoError(t, err)
+	s, err := ParseSigningMessage(msg)
+	require.NoError(t, err)
+
+	require.Equal(t, uint64(736), s.Epoch)
+	require.Equal(t, uint64(134973309), s.SequenceNumber)
+	require.Equal(t, uint64(3407759740), s.NetworkTotalTransactions)
+	require.Equal(t, "e767c5b2706f4d810d28664f9b5b0731d085156a3afcb72ddf67373cdf99057f", hex.EncodeToString(s.ContentDigest[:]))
+	require.NotNil(t, s.PreviousDigest)
+	require.Equal(t, "67d6d26500b1403a1a464cbd64d0aa61e02eace13f2c267f970f70bd3ed4fd38", hex.EncodeToString(s.PreviousDigest[:]))
+	require.Equal(t, uint64(1744911576632), s.TimestampMs)
+	require.Empty(t, s.CheckpointCommitments)
+	require.Nil(t, s.EndOfEpochData)
+	require.Equal(t, []byte{0, 0}, s.VersionSpecificData)
+
+	require.Equal(t, msg, s.SigningMessage())
+	expected := blake2b.Sum256(append([]byte("CheckpointSummary::"), msg[3:len(msg)-8]...))
+	require.Equal(t, Digest(expected), s.Digest())
+
+	_, err = ParseSigningMessage(msg[:len(msg)-1])
+	require.Error(t, err)
+}
+
+func TestCertifiedCheckpointSummaryRoundTrip(t *testing.T) {
+	prev := Digest{1, 2, 3}
+	c := &CertifiedCheckpointSummary{
+		Summary: CheckpointSummary{
+			Epoch:                    736,
+			SequenceNumber:           134999999,
+			NetworkTotalTransactions: 3407800000,
+			ContentDigest:            Digest{4, 5, 6},
+			PreviousDigest:           &prev,
+			EpochRollingGasCostSummary: GasCostSummary{
+				ComputationCost:         1,
+				StorageCost:             2,
+				StorageRebate:           3,
+				NonRefundableStorageFee: 4,
+			},
+			TimestampMs:           1744999999999,
+			CheckpointCommitments: []CheckpointCommitment{{ECMHLiveObjectSetDigest: Digest{7}}},
+			EndOfEpochData: &EndOfEpochData{
+				NextEpochCommittee: []CommitteeMember{
+					{PubKey: AuthorityPublicKeyBytes{0xa0, 1}, Stake: 6000},
+					{PubKey: AuthorityPublicKeyBytes{0xa0, 2}, Stake: 4000},
+				},
+				NextEpochProtocolVersion: 70,
+				EpochCommitments:         []CheckpointCommitment{{ECMHLiveObjectSetDigest: Digest{8}}},
+			},
+		},
+		AuthSignature: AuthorityQuorumSignInfo{
+			Epoch:      736,
+			Signature:  [48]byte{0x94, 0x55},
+			SignersMap: []byte{0x3a, 0x30, 0, 0},
+		},
+	}
+	b := c.MarshalBCS()
+	var decoded CertifiedCheckpointSummary
+	require.NoError(t, decoded.UnmarshalBCS(b))
+	require.Equal(t, c, &decoded)
+
+	next, err := NextCommittee(&decoded.Summary)
+	require.NoError(t, err)
+	require.Equal(t, uint64(737), next.Epoch)
+	require.Equal(t, uint64(10000), next.TotalStake())
+
+	require.Error(t, decoded.UnmarshalBCS(append(b, 0)))
+	require.Error(t, decoded.UnmarshalBCS(b[:len(b)-1]))
+}
+
+func TestCheckpointContentsRoundTrip(t *testing.T) {
+	c := &CheckpointContents{
+		Transactions: []ExecutionDigests{
+			{Transaction: Digest{1}, Effects: Digest{2}},
+			{Transaction: Digest{3}, Effects: Digest{4}},
+		},
+		UserSignatures: [][][]byte{{{0, 1, 2}}, {{3}, {4, 5}}},
+	}
+	b := c.MarshalBCS()
+	var decoded CheckpointContents
+	require.NoError(t, decoded.UnmarshalBCS(b))
+	require.Equal(t, c, &decoded)
+	require.Equal(t, Digest(blake2b.Sum256(append([]byte("CheckpointContents::"), b...))), decoded.Digest())
+}
+
+func TestDigestBase58(t *testing.T) {
+	for _, d := range []Digest{{}, {0, 0, 1}, {0xff, 0xfe}} {
+		parsed, err := ParseDigest(d.String())
+		require.NoError(t, err)
+		require.Equal(t, d, parsed)
+	}
+	require.Equal(t, "11111111111111111111111111111111", Digest{}.String())
+	_, err := ParseDigest("0OIl")
+	require.Error(t, err)
+}
@@ -0,0 +1,83 @@
+package sui
+
+import (
+	"encoding/hex"
+	"fmt"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+)
+
+// AuthorityPublicKeyBytes is a compressed BLS12-381 G2 public key. It is hex encoded in text formats.
+type AuthorityPublicKeyBytes [96]byte
+
+func (k AuthorityPublicKeyBytes) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(k[:])), nil
+}
+
+func (k *AuthorityPublicKeyBytes) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(string(text))
+	if err != nil {
+		return err
+	}
+	if len(b) != len(k) {
+		return fmt.Errorf("invalid public key length %d", len(b))
+	}
+	copy(k[:], b)
+	return nil
+}
+
+type CommitteeMember struct {
+	PubKey AuthorityPublicKeyBytes `json:"pubkey"`
+	Stake  uint64                  `json:"stake"`
+}
+
+// Committee is the set of authorities of an epoch, in the committee order that signers maps index into
+type Committee struct {
+	Epoch   uint64            `json:"epoch"`
+	Members []CommitteeMember `json:"members"`
+}
+
+// NextCommittee returns the committee of the epoch following the end-of-epoch checkpoint s
+func NextCommittee(s *CheckpointSummary) (*Committee, error) {
+	if s.EndOfEpochData == nil {
+		return nil, fmt.Errorf("checkpoint %d is not the last of its epoch", s.SequenceNumber)
+	}
+	members := append([]CommitteeMember{}, s.EndOfEpochData.NextEpochCommittee...)
+	return &Committee{Epoch: s.Epoch + 1, Members: members}, nil
+}
+
+func (c *Committee) TotalStake() uint64 {
+	var total uint64
+	for _, m := range c.Members {
+		total += m.Stake
+	}
+	return total
+}
+
+func (c *Committee) Stakes() []uint64 {
+	stakes := make([]uint64, len(c.Members))
+	for i, m := range c.Members {
+		stakes[i] = m.Stake
+	}
+	return stakes
+}
+
+// PubKeys decompresses the public keys of all members
+func (c *Committee) PubKeys() ([]bls12381.G2Affine, error) {
+	pubkeys := make([]bls12381.G2Affine, len(c.Members))
+	for i := range c.Members {
+		if _, err := pubkeys[i].SetBytes(c.Members[i].PubKey[:]); err != nil {
+			return nil, fmt.Errorf("member %d: %w", i, err)
+		}
+	}
+	return pubkeys, nil
+}
+
+func (m *CommitteeMember) encode(e *encoder) {
+	e.bytes(m.PubKey[:])
+	e.u64(m.Stake)
+}
+
+func (m *CommitteeMember) decode(d *decoder) {
+	d.fixedBytes(m.PubKey[:])
+	m.Stake = d.u64()
+}
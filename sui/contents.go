@@ -0,0 +1,64 @@
+package sui
+
+import "fmt"
+
+type ExecutionDigests struct {
+	Transaction Digest
+	Effects     Digest
+}
+
+// CheckpointContents is CheckpointContents::V1. UserSignatures holds the serialized GenericSignatures of each
+// transaction.
+type CheckpointContents struct {
+	Transactions   []ExecutionDigests
+	UserSignatures [][][]byte
+}
+
+func (c *CheckpointContents) MarshalBCS() []byte {
+	e := &encoder{}
+	e.uleb128(0)
+	e.uleb128(uint64(len(c.Transactions)))
+	for i := range c.Transactions {
+		encodeDigest(e, &c.Transactions[i].Transaction)
+		encodeDigest(e, &c.Transactions[i].Effects)
+	}
+	e.uleb128(uint64(len(c.UserSignatures)))
+	for _, sigs := range c.UserSignatures {
+		e.uleb128(uint64(len(sigs)))
+		for _, sig := range sigs {
+			e.bytes(sig)
+		}
+	}
+	return e.buf
+}
+
+func (c *CheckpointContents) UnmarshalBCS(b []byte) error {
+	d := &decoder{b: b}
+	if variant := d.uleb128(); d.err == nil && variant != 0 {
+		return fmt.Errorf("bcs: unknown CheckpointContents variant %d", variant)
+	}
+	n := d.length()
+	c.Transactions = nil
+	for i := 0; i < n && d.err == nil; i++ {
+		var tx ExecutionDigests
+		decodeDigest(d, &tx.Transaction)
+		decodeDigest(d, &tx.Effects)
+		c.Transactions = append(c.Transactions, tx)
+	}
+	n = d.length()
+	c.UserSignatures = nil
+	for i := 0; i < n && d.err == nil; i++ {
+		m := d.length()
+		var sigs [][]byte
+		for j := 0; j < m && d.err == nil; j++ {
+			sigs = append(sigs, d.bytes())
+		}
+		c.UserSignatures = append(c.UserSignatures, sigs)
+	}
+	return d.finish()
+}
+
+// Digest is the CheckpointContentsDigest, the ContentDigest of the checkpoint
+func (c *CheckpointContents) Digest() Digest {
+	return digestOf("CheckpointContents", c.MarshalBCS())
+}
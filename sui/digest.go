@@ -0,0 +1,100 @@
+package sui
+
+import (
+	"fmt"
+	"golang.org/x/crypto/blake2b"
+	"math/big"
+)
+
+// Digest is a 32-byte Blake2b256 digest. Sui displays digests in base58.
+type Digest [32]byte
+
+func (d Digest) String() string {
+	return base58Encode(d[:])
+}
+
+func ParseDigest(s string) (Digest, error) {
+	var d Digest
+	b, err := base58Decode(s)
+	if err != nil {
+		return d, err
+	}
+	if len(b) != len(d) {
+		return d, fmt.Errorf("invalid digest length %d", len(b))
+	}
+	copy(d[:], b)
+	return d, nil
+}
+
+func (d Digest) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Digest) UnmarshalText(text []byte) error {
+	parsed, err := ParseDigest(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// digestOf computes the digest Sui assigns to a value of the given type: Blake2b256("TypeName::" || bcs)
+func digestOf(typeName string, bcs []byte) Digest {
+	h, _ := blake2b.New256(nil)
+	h.Write([]byte(typeName + "::"))
+	h.Write(bcs)
+	var d Digest
+	copy(d[:], h.Sum(nil))
+	return d
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var base58Index = func() (idx [256]int) {
+	for i := range idx {
+		idx[i] = -1
+	}
+	for i, c := range base58Alphabet {
+		idx[c] = i
+	}
+	return
+}()
+
+func base58Encode(b []byte) string {
+	v := new(big.Int).SetBytes(b)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+	var out []byte
+	for v.Sign() > 0 {
+		v.DivMod(v, radix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for _, c := range b {
+		if c != 0 {
+			break
+		}
+		out = append(out, base58Alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+func base58Decode(s string) ([]byte, error) {
+	v := new(big.Int)
+	radix := big.NewInt(58)
+	for i := 0; i < len(s); i++ {
+		d := base58Index[s[i]]
+		if d < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", s[i])
+		}
+		v.Mul(v, radix).Add(v, big.NewInt(int64(d)))
+	}
+	zeros := 0
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+	return append(make([]byte, zeros), v.Bytes()...), nil
+}
@@ -0,0 +1,257 @@
+package sui
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"slices"
+)
+
+// Signers maps are RoaringBitmaps in the portable serialization format
+// (https://github.com/RoaringBitmap/RoaringFormatSpec). Committees are far smaller than 4096 authorities, so Sui
+// encodes the signers in a single array container, but any valid bitmap, including run and bitmap containers, decodes.
+
+const (
+	roaringSerialCookieNoRun = 12346
+	roaringSerialCookie      = 12347
+	roaringNoOffsetThreshold = 4
+	roaringArrayMaxLen       = 4096
+	roaringBitmapLen         = 8192
+)
+
+// EncodeSignersMap serializes the sorted, deduplicated signer indices as a RoaringBitmap
+func EncodeSignersMap(signers []uint32) []byte {
+	signers = slices.Clone(signers)
+	slices.Sort(signers)
+	signers = slices.Compact(signers)
+
+	// group by high 16 bits, one array or bitmap container per group
+	type container struct {
+		key  uint16
+		lows []uint16
+	}
+	var containers []container
+	for _, s := range signers {
+		key := uint16(s >> 16)
+		if len(containers) == 0 || containers[len(containers)-1].key != key {
+			containers = append(containers, container{key: key})
+		}
+		c := &containers[len(containers)-1]
+		c.lows = append(c.lows, uint16(s))
+	}
+	size := func(c container) int {
+		if len(c.lows) > roaringArrayMaxLen {
+			return roaringBitmapLen
+		}
+		return 2 * len(c.lows)
+	}
+
+	b := binary.LittleEndian.AppendUint32(nil, roaringSerialCookieNoRun)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(containers)))
+	for _, c := range containers {
+		b = binary.LittleEndian.AppendUint16(b, c.key)
+		b = binary.LittleEndian.AppendUint16(b, uint16(len(c.lows)-1))
+	}
+	offset := len(b) + 4*len(containers)
+	for _, c := range containers {
+		b = binary.LittleEndian.AppendUint32(b, uint32(offset))
+		offset += size(c)
+	}
+	for _, c := range containers {
+		if len(c.lows) > roaringArrayMaxLen {
+			words := make([]uint64, roaringBitmapLen/8)
+			for _, low := range c.lows {
+				words[low/64] |= 1 << (low % 64)
+			}
+			for _, w := range words {
+				b = binary.LittleEndian.AppendUint64(b, w)
+			}
+			continue
+		}
+		for _, low := range c.lows {
+			b = binary.LittleEndian.AppendUint16(b, low)
+		}
+	}
+	return b
+}
+
+// DecodeSignersMap deserializes a RoaringBitmap into its sorted signer indices
+func DecodeSignersMap(b []byte) ([]uint32, error) {
+	var signers []uint32
+	err := decodeRoaring(b, func(v uint32) error {
+		signers = append(signers, v)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return signers, nil
+}
+
+// DecodeSignerBits deserializes the signers map of a committee of committeeSize members into a bit vector padded to
+// n, the layout of SigVerifyCircuit.SignerMap. Signers must be members of the committee.
+func DecodeSignerBits(b []byte, committeeSize, n int) ([]bool, error) {
+	if committeeSize < 0 || committeeSize > n {
+		return nil, fmt.Errorf("committee size %d does not fit in %d bits", committeeSize, n)
+	}
+	signed := make([]bool, n)
+	err := decodeRoaring(b, func(v uint32) error {
+		if v >= uint32(committeeSize) {
+			return fmt.Errorf("signer %d out of committee of size %d", v, committeeSize)
+		}
+		signed[v] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return signed, nil
+}
+
+// decodeRoaring calls visit with the values of the RoaringBitmap b in strictly increasing order, stopping at the
+// first error. The encoding must be canonical: sorted keys and values, exact cardinalities and offsets and no
+// trailing bytes.
+func decodeRoaring(b []byte, visit func(v uint32) error) error {
+	r := &roaringReader{b: b}
+	cookie := r.u32()
+	var n int
+	var runFlags []byte
+	hasOffsets := true
+	switch {
+	case r.err != nil:
+		return r.err
+	case cookie == roaringSerialCookieNoRun:
+		n = int(r.u32())
+	case cookie&0xffff == roaringSerialCookie:
+		n = int(cookie>>16) + 1
+		runFlags = r.read((n + 7) / 8)
+		hasOffsets = n >= roaringNoOffsetThreshold
+	default:
+		return fmt.Errorf("roaring: unsupported cookie %#x", cookie)
+	}
+	if r.err == nil && n > len(b) {
+		return fmt.Errorf("roaring: invalid number of containers %d", n)
+	}
+	keys := make([]uint16, n)
+	cards := make([]int, n)
+	for i := 0; i < n && r.err == nil; i++ {
+		keys[i] = r.u16()
+		cards[i] = int(r.u16()) + 1
+		if i > 0 && keys[i] <= keys[i-1] {
+			return errors.New("roaring: keys are not strictly increasing")
+		}
+	}
+	offsets := make([]int, n)
+	for i := 0; i < n && hasOffsets; i++ {
+		offsets[i] = int(r.u32())
+	}
+	if r.err != nil {
+		return r.err
+	}
+
+	last := -1
+	emit := func(v uint32) error {
+		if int(v) <= last {
+			return errors.New("roaring: values are not strictly increasing")
+		}
+		last = int(v)
+		return visit(v)
+	}
+	for i := 0; i < n; i++ {
+		if hasOffsets && offsets[i] != r.off {
+			return fmt.Errorf("roaring: container %d at offset %d, expected %d", i, offsets[i], r.off)
+		}
+		high := uint32(keys[i]) << 16
+		switch {
+		case runFlags != nil && runFlags[i/8]&(1<<(i%8)) != 0:
+			nRuns := int(r.u16())
+			card := 0
+			for j := 0; j < nRuns; j++ {
+				start, length := int(r.u16()), int(r.u16())+1
+				if r.err != nil {
+					return r.err
+				}
+				card += length
+				if start+length > 1<<16 || card > cards[i] {
+					return fmt.Errorf("roaring: run %d of container %d out of bounds", j, i)
+				}
+				for v := start; v < start+length; v++ {
+					if err := emit(high | uint32(v)); err != nil {
+						return err
+					}
+				}
+			}
+			if r.err == nil && card != cards[i] {
+				return fmt.Errorf("roaring: container %d has %d values, expected %d", i, card, cards[i])
+			}
+		case cards[i] <= roaringArrayMaxLen:
+			for j := 0; j < cards[i]; j++ {
+				low := r.u16()
+				if r.err != nil {
+					return r.err
+				}
+				if err := emit(high | uint32(low)); err != nil {
+					return err
+				}
+			}
+		default:
+			words := r.read(roaringBitmapLen)
+			if r.err != nil {
+				return r.err
+			}
+			card := 0
+			for j := 0; j < roaringBitmapLen/8; j++ {
+				for w := binary.LittleEndian.Uint64(words[8*j:]); w != 0; w &= w - 1 {
+					card++
+					if err := emit(high | uint32(64*j+bits.TrailingZeros64(w))); err != nil {
+						return err
+					}
+				}
+			}
+			if card != cards[i] {
+				return fmt.Errorf("roaring: container %d has %d values, expected %d", i, card, cards[i])
+			}
+		}
+	}
+	if r.err != nil {
+		return r.err
+	}
+	if r.off != len(b) {
+		return fmt.Errorf("roaring: %d trailing bytes", len(b)-r.off)
+	}
+	return nil
+}
+
+type roaringReader struct {
+	b   []byte
+	off int
+	err error
+}
+
+func (r *roaringReader) read(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.b)-r.off < n {
+		r.err = errors.New("roaring: unexpected end of input")
+		return nil
+	}
+	b := r.b[r.off : r.off+n]
+	r.off += n
+	return b
+}
+
+func (r *roaringReader) u16() uint16 {
+	if b := r.read(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *roaringReader) u32() uint32 {
+	if b := r.read(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
@@ -0,0 +1,142 @@
+package sui
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"github.com/stretchr/testify/require"
+	"slices"
+	"testing"
+)
+
+func TestSignersMapRoundTrip(t *testing.T) {
+	for _, signers := range [][]uint32{nil, {0}, {1, 2, 3, 112}, {5, 70000}} {
+		decoded, err := DecodeSignersMap(EncodeSignersMap(signers))
+		require.NoError(t, err)
+		require.Equal(t, signers, decoded)
+	}
+
+	// cookie, 1 container, key 0 with 2 values, offset, values 3 and 7
+	b := []byte{0x3a, 0x30, 0, 0, 1, 0, 0, 0, 0, 0, 1, 0, 16, 0, 0, 0, 3, 0, 7, 0}
+	require.Equal(t, b, EncodeSignersMap([]uint32{7, 3, 3}))
+
+	_, err := DecodeSignersMap(b[:len(b)-1])
+	require.Error(t, err)
+	_, err = DecodeSignersMap(append(b, 0))
+	require.Error(t, err)
+}
+
+// Signers map of mainnet checkpoint 134973309, 69 of the 113 members of epoch 736
+const mainnetSignersMap = "3a30000001000000000044001000000001000200030004000500070008000b000d000e00110014001500160019001b001c001d001f002100230024002600280029002b002c002d002e002f003100320033003400380039003b003c003d003e003f004000410042004300450046004b004c004d004e00500052005300540058005a005c005d005f0062006400660067006a006b006c006d006e00"
+
+func TestDecodeSignerBits(t *testing.T) {
+	b, err := hex.DecodeString(mainnetSignersMap)
+	require.NoError(t, err)
+	signed, err := DecodeSignerBits(b, 113, 120)
+	require.NoError(t, err)
+	require.Len(t, signed, 120)
+	var signers []uint32
+	for i, s := range signed {
+		if s {
+			signers = append(signers, uint32(i))
+		}
+	}
+	require.Len(t, signers, 69)
+	require.Equal(t, uint32(110), signers[len(signers)-1])
+	require.Equal(t, b, EncodeSignersMap(signers))
+
+	_, err = DecodeSignerBits(b, 110, 120)
+	require.ErrorContains(t, err, "signer 110 out of committee of size 110")
+	_, err = DecodeSignerBits(b, 121, 120)
+	require.Error(t, err)
+}
+
+func TestDecodeSignersMapContainers(t *testing.T) {
+	// run container: cookie with 1 container, run flags, key 0 with 5 values, runs [1, 3] and [10, 11]
+	b := []byte{0x3b, 0x30, 0, 0, 0x01, 0, 0, 4, 0, 2, 0, 1, 0, 2, 0, 10, 0, 1, 0}
+	signers, err := DecodeSignersMap(b)
+	require.NoError(t, err)
+	require.Equal(t, []uint32{1, 2, 3, 10, 11}, signers)
+	signed, err := DecodeSignerBits(b, 12, 16)
+	require.NoError(t, err)
+	require.Equal(t, []bool{false, true, true, true, false, false, false, false, false, false, true, true, false, false, false, false}, signed)
+	_, err = DecodeSignerBits(b, 11, 16)
+	require.Error(t, err)
+
+	// the cardinality must match the runs
+	bad := slices.Clone(b)
+	bad[7] = 5
+	_, err = DecodeSignersMap(bad)
+	require.Error(t, err)
+	// runs must not overlap
+	bad = slices.Clone(b)
+	bad[15] = 2
+	_, err = DecodeSignersMap(bad)
+	require.Error(t, err)
+
+	// bitmap container
+	signers = nil
+	for i := uint32(0); i < 2*roaringArrayMaxLen; i += 2 {
+		signers = append(signers, i)
+	}
+	signers = append(signers, 1<<16|5)
+	b = EncodeSignersMap(signers)
+	require.Len(t, b, 8+4*2+4*2+roaringBitmapLen+2)
+	decoded, err := DecodeSignersMap(b)
+	require.NoError(t, err)
+	require.Equal(t, signers, decoded)
+
+	// keys must be strictly increasing
+	b = EncodeSignersMap([]uint32{1, 1 << 16})
+	b[12] = 0
+	_, err = DecodeSignersMap(b)
+	require.Error(t, err)
+}
+
+func FuzzDecodeSignerBits(f *testing.F) {
+	mainnet, err := hex.DecodeString(mainnetSignersMap)
+	require.NoError(f, err)
+	f.Add(mainnet, uint8(113))
+	f.Add(EncodeSignersMap(nil), uint8(0))
+	f.Add(EncodeSignersMap([]uint32{0, 3, 7}), uint8(4))
+	f.Add([]byte{0x3b, 0x30, 0, 0, 0x01, 0, 0, 4, 0, 2, 0, 1, 0, 2, 0, 10, 0, 1, 0}, uint8(12))
+	f.Fuzz(func(t *testing.T, b []byte, committeeSize uint8) {
+		signed, err := DecodeSignerBits(b, int(committeeSize), 256)
+		if err != nil {
+			return
+		}
+		signers, err := DecodeSignersMap(b)
+		require.NoError(t, err)
+		var expected []uint32
+		for i, s := range signed {
+			if s {
+				require.Less(t, i, int(committeeSize))
+				expected = append(expected, uint32(i))
+			}
+		}
+		require.Equal(t, expected, signers)
+		// any valid encoding decodes to the same signers as the canonical one
+		decoded, err := DecodeSignersMap(EncodeSignersMap(signers))
+		require.NoError(t, err)
+		require.Equal(t, signers, decoded)
+	})
+}
+
+func FuzzSignersMapRoundTrip(f *testing.F) {
+	f.Add([]byte{0, 1, 2, 3})
+	f.Add([]byte{0x70, 0, 0, 0, 0x71, 0, 1, 0})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		var signers []uint32
+		for i := 0; i+4 <= len(b); i += 4 {
+			// keep values within a few containers
+			signers = append(signers, binary.LittleEndian.Uint32(b[i:])%(1<<18))
+		}
+		decoded, err := DecodeSignersMap(EncodeSignersMap(signers))
+		require.NoError(t, err)
+		slices.Sort(signers)
+		signers = slices.Compact(signers)
+		if len(signers) == 0 {
+			signers = nil
+		}
+		require.Equal(t, signers, decoded)
+	})
+}
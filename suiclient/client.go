@@ -0,0 +1,257 @@
+// Package suiclient reads checkpoints and committees from a Sui full node
+package suiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/patrickmao1/zuika/sui"
+	"io"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Client talks to the JSON-RPC API of a full node and, for signed checkpoint summaries, to its REST API
+type Client struct {
+	URL string
+	// Base URL of the REST API, e.g. http://localhost:9000/v2. The JSON-RPC API does not return the signers map nor
+	// the version specific data of a checkpoint, so certified checkpoints are read in BCS from the REST API.
+	RESTURL string
+	HTTP    *http.Client
+	// Number of attempts of a request failing with a transport error or a 429 or 5xx status, three if zero
+	MaxAttempts int
+	// Backoff before the first retry, doubled on every retry, half a second if zero
+	RetryBackoff time.Duration
+
+	id atomic.Uint64
+}
+
+// RPCError is an error returned by the node. It is not retried.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// statusError is a non-200 HTTP status
+type statusError struct {
+	status int
+	body   string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s: %s", http.StatusText(e.status), e.body)
+}
+
+func (e *statusError) retryable() bool {
+	return e.status == http.StatusTooManyRequests || e.status >= 500
+}
+
+func (c *Client) GetLatestCheckpointSequenceNumber(ctx context.Context) (uint64, error) {
+	var seq u64
+	if err := c.call(ctx, "sui_getLatestCheckpointSequenceNumber", &seq); err != nil {
+		return 0, err
+	}
+	return uint64(seq), nil
+}
+
+func (c *Client) GetCheckpoint(ctx context.Context, seq uint64) (*Checkpoint, error) {
+	var res checkpointJSON
+	if err := c.call(ctx, "sui_getCheckpoint", &res, strconv.FormatUint(seq, 10)); err != nil {
+		return nil, err
+	}
+	cp, err := res.checkpoint()
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint %d: %w", seq, err)
+	}
+	if cp.Summary.SequenceNumber != seq {
+		return nil, fmt.Errorf("asked for checkpoint %d, got %d", seq, cp.Summary.SequenceNumber)
+	}
+	return cp, nil
+}
+
+// GetCheckpoints returns the n checkpoints starting at from in ascending order, following as many pages as needed.
+// Fewer checkpoints are returned if the node has no more.
+func (c *Client) GetCheckpoints(ctx context.Context, from uint64, n int) ([]*Checkpoint, error) {
+	var cursor *string
+	if from > 0 {
+		// The cursor is exclusive
+		s := strconv.FormatUint(from-1, 10)
+		cursor = &s
+	}
+	var res []*Checkpoint
+	for len(res) < n {
+		var page checkpointPageJSON
+		if err := c.call(ctx, "sui_getCheckpoints", &page, cursor, n-len(res), false); err != nil {
+			return nil, err
+		}
+		for i := range page.Data {
+			cp, err := page.Data[i].checkpoint()
+			if err != nil {
+				return nil, fmt.Errorf("checkpoint %d: %w", uint64(page.Data[i].SequenceNumber), err)
+			}
+			if want := from + uint64(len(res)); cp.Summary.SequenceNumber != want {
+				return nil, fmt.Errorf("expected checkpoint %d, got %d", want, cp.Summary.SequenceNumber)
+			}
+			res = append(res, cp)
+		}
+		if !page.HasNextPage || page.NextCursor == nil || len(page.Data) == 0 {
+			break
+		}
+		s := strconv.FormatUint(uint64(*page.NextCursor), 10)
+		cursor = &s
+	}
+	if len(res) > n {
+		res = res[:n]
+	}
+	return res, nil
+}
+
+// GetCommitteeInfo returns the committee of epoch with its members in committee order
+func (c *Client) GetCommitteeInfo(ctx context.Context, epoch uint64) (*sui.Committee, error) {
+	var res committeeInfoJSON
+	if err := c.call(ctx, "suix_getCommitteeInfo", &res, strconv.FormatUint(epoch, 10)); err != nil {
+		return nil, err
+	}
+	if uint64(res.Epoch) != epoch {
+		return nil, fmt.Errorf("asked for the committee of epoch %d, got %d", epoch, uint64(res.Epoch))
+	}
+	committee := &sui.Committee{Epoch: epoch, Members: make([]sui.CommitteeMember, len(res.Validators))}
+	for i, m := range res.Validators {
+		committee.Members[i] = sui.CommitteeMember(m)
+	}
+	return committee, nil
+}
+
+// GetCertifiedCheckpoint returns the checkpoint summary with the aggregated signature of the committee. The summary
+// is read from the REST API and checked against the digest and signature the JSON-RPC API reports for it.
+func (c *Client) GetCertifiedCheckpoint(ctx context.Context, seq uint64) (*sui.CertifiedCheckpointSummary, error) {
+	if c.RESTURL == "" {
+		return nil, errors.New("no REST URL")
+	}
+	b, err := c.retry(ctx, func() ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/checkpoints/%d", c.RESTURL, seq), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/bcs")
+		return c.do(req)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint %d: %w", seq, err)
+	}
+	certified := new(sui.CertifiedCheckpointSummary)
+	if err := certified.UnmarshalBCS(b); err != nil {
+		return nil, fmt.Errorf("checkpoint %d: %w", seq, err)
+	}
+
+	cp, err := c.GetCheckpoint(ctx, seq)
+	if err != nil {
+		return nil, err
+	}
+	if certified.Summary.SequenceNumber != seq {
+		return nil, fmt.Errorf("asked for checkpoint %d, got %d", seq, certified.Summary.SequenceNumber)
+	}
+	if digest := certified.Summary.Digest(); digest != cp.Digest {
+		return nil, fmt.Errorf("checkpoint %d: digest %s, expected %s", seq, digest, cp.Digest)
+	}
+	if certified.AuthSignature.Epoch != certified.Summary.Epoch || certified.AuthSignature.Signature != cp.ValidatorSignature {
+		return nil, fmt.Errorf("checkpoint %d: signature does not match the one of the JSON-RPC API", seq)
+	}
+	return certified, nil
+}
+
+func (c *Client) call(ctx context.Context, method string, result any, params ...any) error {
+	if params == nil {
+		params = []any{}
+	}
+	body, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      c.id.Add(1),
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+	b, err := c.retry(ctx, func() ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return c.do(req)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	var res struct {
+		Result json.RawMessage `json:"result"`
+		Error  *RPCError       `json:"error"`
+	}
+	if err := json.Unmarshal(b, &res); err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	if res.Error != nil {
+		return fmt.Errorf("%s: %w", method, res.Error)
+	}
+	if err := json.Unmarshal(res.Result, result); err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	return nil
+}
+
+// retry runs f until it succeeds, fails with an error that is not retried or runs out of attempts
+func (c *Client) retry(ctx context.Context, f func() ([]byte, error)) ([]byte, error) {
+	attempts := c.MaxAttempts
+	if attempts == 0 {
+		attempts = 3
+	}
+	backoff := c.RetryBackoff
+	if backoff == 0 {
+		backoff = 500 * time.Millisecond
+	}
+	for i := 1; ; i++ {
+		b, err := f()
+		if err == nil {
+			return b, nil
+		}
+		var se *statusError
+		if i == attempts || ctx.Err() != nil || (errors.As(err, &se) && !se.retryable()) {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (c *Client) do(req *http.Request) ([]byte, error) {
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, &statusError{status: res.StatusCode, body: string(bytes.TrimSpace(b))}
+	}
+	return b, nil
+}
@@ -0,0 +1,190 @@
+package suiclient
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/patrickmao1/zuika/sui"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fullNode replays the responses in testdata. They are shaped like a full node's responses for checkpoint 134973309
+// of epoch 736 and built from the same checkpoint and committee as the circuit tests. The checkpoints before it
+// returned by sui_getCheckpoints and the transaction digests are made up.
+type fullNode struct {
+	t *testing.T
+
+	mu sync.Mutex
+	// Number of requests to fail with 503 before serving
+	failures int
+	requests int
+}
+
+func newFullNode(t *testing.T) (*fullNode, *Client) {
+	n := &fullNode{t: t}
+	srv := httptest.NewServer(n)
+	t.Cleanup(srv.Close)
+	return n, &Client{URL: srv.URL, RESTURL: srv.URL + "/v2", RetryBackoff: time.Millisecond}
+}
+
+func (n *fullNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n.mu.Lock()
+	n.requests++
+	if n.failures > 0 {
+		n.failures--
+		n.mu.Unlock()
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+		return
+	}
+	n.mu.Unlock()
+
+	if seq, ok := strings.CutPrefix(r.URL.Path, "/v2/checkpoints/"); ok {
+		require.Equal(n.t, "application/bcs", r.Header.Get("Accept"))
+		b, err := os.ReadFile(filepath.Join("testdata", "checkpoint_"+seq+".bcs"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(b)
+		return
+	}
+
+	var req struct {
+		ID     uint64            `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	require.NoError(n.t, json.NewDecoder(r.Body).Decode(&req))
+	name := req.Method
+	if len(req.Params) > 0 {
+		var param *string
+		require.NoError(n.t, json.Unmarshal(req.Params[0], &param))
+		if param == nil {
+			name += "_first"
+		} else {
+			name += "_" + *param
+		}
+	}
+	b, err := os.ReadFile(filepath.Join("testdata", name+".json"))
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"error":   RPCError{Code: -32602, Message: fmt.Sprintf("no response for %s", name)},
+		})
+		return
+	}
+	w.Write(b)
+}
+
+func TestGetCheckpoint(t *testing.T) {
+	_, c := newFullNode(t)
+	ctx := context.Background()
+
+	latest, err := c.GetLatestCheckpointSequenceNumber(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(134973309), latest)
+
+	cp, err := c.GetCheckpoint(ctx, latest)
+	require.NoError(t, err)
+	require.Equal(t, uint64(736), cp.Summary.Epoch)
+	require.Equal(t, uint64(134973309), cp.Summary.SequenceNumber)
+	require.Equal(t, uint64(3407759740), cp.Summary.NetworkTotalTransactions)
+	require.Equal(t, uint64(1744911576632), cp.Summary.TimestampMs)
+	require.Equal(t, "7zLyypAV98mMPPbaj1YyfxsoPpkXtebxFCyVQgXTZazB", cp.Summary.PreviousDigest.String())
+	require.Equal(t, "C9gQ2zhGhdfKZB6pze9BXYwT9pjsPKk94H5uHc7ZDeWJ", cp.Digest.String())
+	require.Len(t, cp.Transactions, 1)
+	require.Nil(t, cp.Summary.EndOfEpochData)
+	require.Equal(t, "9455fd6e9ccdc6157cabf28b7a8e2e161d17a2b167ecaf055b8677f1c43365418edb71fc11b1160405cac49b8c8b1d08",
+		hex.EncodeToString(cp.ValidatorSignature[:]))
+
+	_, err = c.GetCheckpoint(ctx, 1)
+	var rpcErr *RPCError
+	require.ErrorAs(t, err, &rpcErr)
+	require.Equal(t, -32602, rpcErr.Code)
+}
+
+func TestGetCertifiedCheckpoint(t *testing.T) {
+	_, c := newFullNode(t)
+	certified, err := c.GetCertifiedCheckpoint(context.Background(), 134973309)
+	require.NoError(t, err)
+	require.Equal(t, uint64(134973309), certified.Summary.SequenceNumber)
+	require.Equal(t, "C9gQ2zhGhdfKZB6pze9BXYwT9pjsPKk94H5uHc7ZDeWJ", certified.Summary.Digest().String())
+	require.Equal(t, uint64(736), certified.AuthSignature.Epoch)
+	signers, err := sui.DecodeSignersMap(certified.AuthSignature.SignersMap)
+	require.NoError(t, err)
+	require.Len(t, signers, 69)
+
+	// A summary that does not hash to the digest of the JSON-RPC API is rejected
+	dir := t.TempDir()
+	certified.Summary.TimestampMs++
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "checkpoints"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "checkpoints", "134973309"), certified.MarshalBCS(), 0644))
+	rest := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer rest.Close()
+	c.RESTURL = rest.URL
+	_, err = c.GetCertifiedCheckpoint(context.Background(), 134973309)
+	require.ErrorContains(t, err, "digest")
+}
+
+func TestGetCheckpoints(t *testing.T) {
+	_, c := newFullNode(t)
+	cps, err := c.GetCheckpoints(context.Background(), 134973306, 10)
+	require.NoError(t, err)
+	require.Len(t, cps, 4)
+	for i, cp := range cps {
+		require.Equal(t, uint64(134973306+i), cp.Summary.SequenceNumber)
+		if i > 0 {
+			require.Equal(t, cps[i-1].Digest, *cp.Summary.PreviousDigest)
+		}
+	}
+	require.Equal(t, "C9gQ2zhGhdfKZB6pze9BXYwT9pjsPKk94H5uHc7ZDeWJ", cps[3].Digest.String())
+
+	cps, err = c.GetCheckpoints(context.Background(), 134973306, 3)
+	require.NoError(t, err)
+	require.Len(t, cps, 3)
+}
+
+func TestGetCommitteeInfo(t *testing.T) {
+	_, c := newFullNode(t)
+	committee, err := c.GetCommitteeInfo(context.Background(), 736)
+	require.NoError(t, err)
+	require.Equal(t, uint64(736), committee.Epoch)
+	require.Len(t, committee.Members, 113)
+	require.Equal(t, uint64(150), committee.Members[0].Stake)
+	require.Equal(t, "80477c651291fe6e", hex.EncodeToString(committee.Members[0].PubKey[:8]))
+	require.Equal(t, uint64(10000), committee.TotalStake())
+	_, err = committee.PubKeys()
+	require.NoError(t, err)
+}
+
+func TestRetry(t *testing.T) {
+	n, c := newFullNode(t)
+	n.failures = 2
+	_, err := c.GetLatestCheckpointSequenceNumber(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, n.requests)
+
+	n.requests, n.failures = 0, 3
+	_, err = c.GetLatestCheckpointSequenceNumber(context.Background())
+	var se *statusError
+	require.True(t, errors.As(err, &se))
+	require.Equal(t, http.StatusServiceUnavailable, se.status)
+	require.Equal(t, 3, n.requests)
+
+	// Errors of the node are not retried
+	n.requests, n.failures = 0, 0
+	_, err = c.GetCommitteeInfo(context.Background(), 1)
+	require.Error(t, err)
+	require.Equal(t, 1, n.requests)
+}
@@ -0,0 +1,167 @@
+package suiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/patrickmao1/zuika/sui"
+	"strconv"
+)
+
+// Checkpoint is a checkpoint as returned by sui_getCheckpoint. See Client.GetCertifiedCheckpoint for the signed
+// summary that the prover takes.
+type Checkpoint struct {
+	// The JSON-RPC API does not return the content digest nor the version specific data, they are left zero
+	Summary            sui.CheckpointSummary
+	Digest             sui.Digest
+	Transactions       []sui.Digest
+	ValidatorSignature [48]byte
+}
+
+// u64 is a u64 encoded as a JSON string, which is how the JSON-RPC API encodes BigInt<u64>
+type u64 uint64
+
+func (v *u64) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		// Some fields are plain numbers
+		var n uint64
+		if err := json.Unmarshal(b, &n); err != nil {
+			return fmt.Errorf("invalid u64 %s", b)
+		}
+		*v = u64(n)
+		return nil
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return err
+	}
+	*v = u64(n)
+	return nil
+}
+
+type gasCostSummaryJSON struct {
+	ComputationCost         u64 `json:"computationCost"`
+	StorageCost             u64 `json:"storageCost"`
+	StorageRebate           u64 `json:"storageRebate"`
+	NonRefundableStorageFee u64 `json:"nonRefundableStorageFee"`
+}
+
+type commitmentJSON struct {
+	ECMHLiveObjectSetDigest *struct {
+		Digest sui.Digest `json:"digest"`
+	} `json:"ECMHLiveObjectSetDigest"`
+}
+
+// memberJSON is a committee member encoded as a [base64 public key, stake] pair
+type memberJSON sui.CommitteeMember
+
+func (m *memberJSON) UnmarshalJSON(b []byte) error {
+	var pair struct {
+		PubKey []byte
+		Stake  u64
+	}
+	var raw [2]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw[0], &pair.PubKey); err != nil {
+		return fmt.Errorf("public key: %w", err)
+	}
+	if err := json.Unmarshal(raw[1], &pair.Stake); err != nil {
+		return fmt.Errorf("stake: %w", err)
+	}
+	if len(pair.PubKey) != len(m.PubKey) {
+		return fmt.Errorf("invalid public key length %d", len(pair.PubKey))
+	}
+	copy(m.PubKey[:], pair.PubKey)
+	m.Stake = uint64(pair.Stake)
+	return nil
+}
+
+type endOfEpochDataJSON struct {
+	NextEpochCommittee       []memberJSON     `json:"nextEpochCommittee"`
+	NextEpochProtocolVersion u64              `json:"nextEpochProtocolVersion"`
+	EpochCommitments         []commitmentJSON `json:"epochCommitments"`
+}
+
+type checkpointJSON struct {
+	Epoch                      u64                 `json:"epoch"`
+	SequenceNumber             u64                 `json:"sequenceNumber"`
+	Digest                     sui.Digest          `json:"digest"`
+	NetworkTotalTransactions   u64                 `json:"networkTotalTransactions"`
+	PreviousDigest             *sui.Digest         `json:"previousDigest"`
+	EpochRollingGasCostSummary gasCostSummaryJSON  `json:"epochRollingGasCostSummary"`
+	TimestampMs                u64                 `json:"timestampMs"`
+	EndOfEpochData             *endOfEpochDataJSON `json:"endOfEpochData"`
+	Transactions               []sui.Digest        `json:"transactions"`
+	CheckpointCommitments      []commitmentJSON    `json:"checkpointCommitments"`
+	ValidatorSignature         []byte              `json:"validatorSignature"`
+}
+
+func (c *checkpointJSON) checkpoint() (*Checkpoint, error) {
+	checkpointCommitments, err := commitments(c.CheckpointCommitments)
+	if err != nil {
+		return nil, err
+	}
+	g := &c.EpochRollingGasCostSummary
+	cp := &Checkpoint{
+		Summary: sui.CheckpointSummary{
+			Epoch:                    uint64(c.Epoch),
+			SequenceNumber:           uint64(c.SequenceNumber),
+			NetworkTotalTransactions: uint64(c.NetworkTotalTransactions),
+			PreviousDigest:           c.PreviousDigest,
+			EpochRollingGasCostSummary: sui.GasCostSummary{
+				ComputationCost:         uint64(g.ComputationCost),
+				StorageCost:             uint64(g.StorageCost),
+				StorageRebate:           uint64(g.StorageRebate),
+				NonRefundableStorageFee: uint64(g.NonRefundableStorageFee),
+			},
+			TimestampMs:           uint64(c.TimestampMs),
+			CheckpointCommitments: checkpointCommitments,
+		},
+		Digest:       c.Digest,
+		Transactions: c.Transactions,
+	}
+	if len(c.ValidatorSignature) != len(cp.ValidatorSignature) {
+		return nil, fmt.Errorf("invalid validator signature length %d", len(c.ValidatorSignature))
+	}
+	copy(cp.ValidatorSignature[:], c.ValidatorSignature)
+	if eoe := c.EndOfEpochData; eoe != nil {
+		epochCommitments, err := commitments(eoe.EpochCommitments)
+		if err != nil {
+			return nil, err
+		}
+		members := make([]sui.CommitteeMember, len(eoe.NextEpochCommittee))
+		for i, m := range eoe.NextEpochCommittee {
+			members[i] = sui.CommitteeMember(m)
+		}
+		cp.Summary.EndOfEpochData = &sui.EndOfEpochData{
+			NextEpochCommittee:       members,
+			NextEpochProtocolVersion: uint64(eoe.NextEpochProtocolVersion),
+			EpochCommitments:         epochCommitments,
+		}
+	}
+	return cp, nil
+}
+
+func commitments(cs []commitmentJSON) ([]sui.CheckpointCommitment, error) {
+	var res []sui.CheckpointCommitment
+	for i, c := range cs {
+		if c.ECMHLiveObjectSetDigest == nil {
+			return nil, fmt.Errorf("unsupported checkpoint commitment %d", i)
+		}
+		res = append(res, sui.CheckpointCommitment{ECMHLiveObjectSetDigest: c.ECMHLiveObjectSetDigest.Digest})
+	}
+	return res, nil
+}
+
+type checkpointPageJSON struct {
+	Data        []checkpointJSON `json:"data"`
+	NextCursor  *u64             `json:"nextCursor"`
+	HasNextPage bool             `json:"hasNextPage"`
+}
+
+type committeeInfoJSON struct {
+	Epoch      u64          `json:"epoch"`
+	Validators []memberJSON `json:"validators"`
+}
@@ -2,125 +2,86 @@ package tests
 
 import (
 	"encoding/hex"
-	"fmt"
 	"github.com/consensys/gnark-crypto/ecc"
-	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
-	"github.com/consensys/gnark-crypto/field/hash"
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/groth16"
 	bn254 "github.com/consensys/gnark/backend/groth16/bn254"
 	"github.com/consensys/gnark/backend/solidity"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/r1cs"
-	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
 	"github.com/consensys/gnark/test"
+	"github.com/patrickmao1/zuika/artifacts"
 	"github.com/patrickmao1/zuika/circuits"
+	"github.com/patrickmao1/zuika/sui"
 	"github.com/patrickmao1/zuika/utils"
 	"github.com/stretchr/testify/require"
-	"math/big"
 	"os"
 	"testing"
-	"time"
 )
 
+const manifestPath = "../build/manifest.json"
+
 func TestCompileAndSetup(t *testing.T) {
 	c := buildTestCircuit(t)
 	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, c)
 	require.NoError(t, err)
-	fmt.Println("constraints", ccs.GetNbConstraints())
-
-	// save ccs
-	f, err := os.OpenFile("../build/ccs", os.O_RDWR|os.O_CREATE, 0666)
-	require.NoError(t, err)
-	_, err = ccs.WriteTo(f)
-	require.NoError(t, err)
-	fmt.Println("ccs saved")
+	t.Log("constraints", ccs.GetNbConstraints())
+	require.NoError(t, os.MkdirAll("../build", 0755))
+	m := artifacts.NewManifest(artifacts.SigVerifyParams(testParams), ccs.GetNbConstraints())
+	saveArtifact(t, "../build/ccs", ccs)
+	require.NoError(t, m.Add(manifestPath, artifacts.CCS, "../build/ccs"))
 
-	// setup
-	fmt.Println("setup")
 	pk, vk, err := groth16.Setup(ccs)
 	require.NoError(t, err)
+	saveArtifact(t, "../build/pk", pk)
+	saveArtifact(t, "../build/vk", vk)
+	require.NoError(t, m.Add(manifestPath, artifacts.PK, "../build/pk"))
+	require.NoError(t, m.Add(manifestPath, artifacts.VK, "../build/vk"))
 
-	// save pk
-	f, err = os.OpenFile("../build/pk", os.O_RDWR|os.O_CREATE, 0666)
-	require.NoError(t, err)
-	_, err = pk.WriteTo(f)
-	require.NoError(t, err)
-	fmt.Println("pk saved")
-
-	// save vk
-	f, err = os.OpenFile("../build/vk", os.O_RDWR|os.O_CREATE, 0666)
-	require.NoError(t, err)
-	_, err = vk.WriteTo(f)
-	fmt.Println("vk saved")
-
-	// save solidity verifier
-	fmt.Println("export solidity")
-	f, err = os.OpenFile("../build/committee_sig_contract.sol", os.O_RDWR|os.O_CREATE, 0666)
+	fingerprint, source, err := artifacts.SolidityFingerprint(vk)
 	require.NoError(t, err)
-	err = vk.ExportSolidity(f)
-	require.NoError(t, err)
-	fmt.Println("exported solidity")
+	require.NoError(t, os.WriteFile("../build/committee_sig_contract.sol", source, 0644))
+	m.SolidityFingerprint = fingerprint
+	require.NoError(t, m.WriteFile(manifestPath))
 }
 
 func TestProve(t *testing.T) {
 	a := buildTestCircuit(t)
 
+	m, err := artifacts.ReadManifest(manifestPath)
+	require.NoError(t, err)
+	require.NoError(t, m.Check(artifacts.SigVerifyParams(testParams)))
+	for name, path := range map[string]string{artifacts.CCS: "../build/ccs", artifacts.PK: "../build/pk", artifacts.VK: "../build/vk"} {
+		require.NoError(t, m.CheckFile(name, path))
+	}
 	ccs := groth16.NewCS(ecc.BN254)
 	pk := groth16.NewProvingKey(ecc.BN254)
 	vk := groth16.NewVerifyingKey(ecc.BN254)
+	loadArtifact(t, "../build/ccs", ccs)
+	loadArtifact(t, "../build/pk", pk)
+	loadArtifact(t, "../build/vk", vk)
 
-	// read ccs
-	f, err := os.OpenFile("../build/ccs", os.O_RDONLY, 0666)
-	require.NoError(t, err)
-	_, err = ccs.ReadFrom(f)
-	require.NoError(t, err)
-
-	// read pk
-	f, err = os.OpenFile("../build/pk", os.O_RDONLY, 0666)
-	_, err = pk.ReadFrom(f)
-	require.NoError(t, err)
-
-	// read vk
-	f, err = os.OpenFile("../build/vk", os.O_RDONLY, 0666)
-	_, err = vk.ReadFrom(f)
-	require.NoError(t, err)
-
-	// generate witness
-	fmt.Println("witness")
 	w, err := frontend.NewWitness(a, ecc.BN254.ScalarField())
 	require.NoError(t, err)
 	wpub, err := w.Public()
 	require.NoError(t, err)
 
-	// prove
-	fmt.Println("prove")
 	proof, err := groth16.Prove(ccs, pk, w, solidity.WithProverTargetSolidityVerifier(backend.GROTH16))
 	require.NoError(t, err)
-	f, err = os.OpenFile("../build/proof_"+time.Now().String(), os.O_RDWR|os.O_CREATE, 0666)
-	require.NoError(t, err)
-	_, err = proof.WriteTo(f)
-	require.NoError(t, err)
-
-	fmt.Println("verify")
+	saveArtifact(t, "../build/proof", proof)
 	err = groth16.Verify(proof, vk, wpub, solidity.WithVerifierTargetSolidityVerifier(backend.GROTH16))
 	require.NoError(t, err)
 
 	pubBytes, err := wpub.MarshalBinary()
 	require.NoError(t, err)
-	fmt.Printf("pub input bytes %x\n", pubBytes)
-
-	pb := proof.(*bn254.Proof).MarshalSolidity()
-	fmt.Printf("marshal solidity %x\n", pb)
-
+	t.Logf("pub input bytes %x", pubBytes)
+	t.Logf("marshal solidity %x", proof.(*bn254.Proof).MarshalSolidity())
 	p, commitments, commitmentPoK := utils.ExportProofForSolidity(proof)
 	for i, v := range p {
-		fmt.Printf("p[%d] %x\n", i, v)
+		t.Logf("p[%d] %x", i, v)
 	}
-	fmt.Printf("commitments[0] %x", commitments[0].Bytes())
-	fmt.Printf("commitments[1] %x", commitments[1].Bytes())
-	fmt.Printf("commitmentPoK[0] %x", commitmentPoK[0].Bytes())
-	fmt.Printf("commitmentPoK[1] %x", commitmentPoK[1].Bytes())
+	t.Logf("commitments %x %x", commitments[0], commitments[1])
+	t.Logf("commitmentPoK %x %x", commitmentPoK[0], commitmentPoK[1])
 }
 
 func TestSigVerify(t *testing.T) {
@@ -130,88 +91,63 @@ func TestSigVerify(t *testing.T) {
 	assert.SolvingSucceeded(c, a, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
 }
 
-func buildTestCircuit(t *testing.T) *circuits.SigVerifyCircuit {
-	const numMaxAuthorities = 120
-	//const numMaxAuthorities = 1
-
-	fmt.Println("num authorities", len(pubkeysHex))
-	pubkeyBytes := make([][]byte, len(pubkeysHex))
-	for i := range pubkeyBytes {
-		bs, err := hex.DecodeString(pubkeysHex[i])
-		require.NoError(t, err)
-		pubkeyBytes[i] = bs
-	}
-
-	_signerMap := []frontend.Variable{0, 1, 1, 1, 1, 1, 0, 1, 1, 0, 0, 1, 0, 1, 1, 0, 0, 1, 0, 0, 1, 1, 1, 0, 0, 1, 0, 1, 1, 1, 0, 1, 0, 1, 0, 1, 1, 0, 1, 0, 1, 1, 0, 1, 1, 1, 1, 1, 0, 1, 1, 1, 1, 0, 0, 0, 1, 1, 0, 1, 1, 1, 1, 1, 1, 1, 1, 1, 0, 1, 1, 0, 0, 0, 0, 1, 1, 1, 1, 0, 1, 0, 1, 1, 1, 0, 0, 0, 1, 0, 1, 0, 1, 1, 0, 1, 0, 0, 1, 0, 1, 0, 1, 1, 0, 0, 1, 1, 1, 1, 1, 0, 0}
-	signerMap := make([]frontend.Variable, numMaxAuthorities)
-	for i := range signerMap {
-		signerMap[i] = 0
-	}
-	copy(signerMap, _signerMap)
+func TestSigVerifyExpandInCircuit(t *testing.T) {
+	p := circuits.SigVerifyParams{MaxAuthorities: 120, ExpandInCircuit: true}
+	c := buildTestCircuitWithParams(t, p)
+	a := buildTestCircuitWithParams(t, p)
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(c, a, test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254))
+}
 
-	pubkeys := make([]sw_bls12381.G2Affine, numMaxAuthorities)
-	aggPubKey := new(bls12381.G2Affine).SetInfinity()
-	for i := range pubkeys {
-		var pubkeyPoint bls12381.G2Affine
+// testParams are the parameters of the circuit whose artifacts TestCompileAndSetup writes to ../build
+var testParams = circuits.SigVerifyParams{MaxAuthorities: 120}
 
-		if i < len(pubkeyBytes) {
-			bs := pubkeyBytes[i]
-			_, err := pubkeyPoint.SetBytes(bs)
-			require.NoError(t, err)
-		} else {
-			pubkeyPoint.SetInfinity()
-		}
-		if signerMap[i] == 1 {
-			aggPubKey.Add(aggPubKey, &pubkeyPoint)
-		}
-		pubkey := sw_bls12381.NewG2Affine(pubkeyPoint)
-		pubkeys[i] = pubkey
-	}
+func buildTestCircuit(t *testing.T) *circuits.SigVerifyCircuit {
+	return buildTestCircuitWithParams(t, testParams)
+}
 
-	sigBytes, err := hex.DecodeString("9455fd6e9ccdc6157cabf28b7a8e2e161d17a2b167ecaf055b8677f1c43365418edb71fc11b1160405cac49b8c8b1d08")
+func buildTestCircuitWithParams(t *testing.T, p circuits.SigVerifyParams) *circuits.SigVerifyCircuit {
+	committee := testCommittee(t)
+	a, err := circuits.NewSigVerifyAssignment(committee, testCertifiedCheckpoint(t), p)
 	require.NoError(t, err)
-	var sigG1 bls12381.G1Affine
-	_, err = sigG1.SetBytes(sigBytes)
+	root, err := circuits.SuiCommitteeRoot(committee, p.MaxAuthorities)
 	require.NoError(t, err)
-	sig := sw_bls12381.NewG1Affine(sigG1)
+	require.Equal(t, root, a.CommitteeRoot)
+	return a
+}
 
-	if len(stakes) < numMaxAuthorities {
-		stakes = append(stakes, make([]int, numMaxAuthorities-len(stakes))...)
-	}
-	committeeStakeUnits := make([]frontend.Variable, numMaxAuthorities)
-	for i := range committeeStakeUnits {
-		committeeStakeUnits[i] = stakes[i]
+func testCommittee(t *testing.T) *sui.Committee {
+	c := &sui.Committee{Epoch: 736, Members: make([]sui.CommitteeMember, len(pubkeysHex))}
+	for i := range pubkeysHex {
+		bs, err := hex.DecodeString(pubkeysHex[i])
+		require.NoError(t, err)
+		copy(c.Members[i].PubKey[:], bs)
+		c.Members[i].Stake = uint64(stakes[i])
 	}
+	return c
+}
 
+func testCertifiedCheckpoint(t *testing.T) *sui.CertifiedCheckpointSummary {
 	chkBytes, err := hex.DecodeString("020000e0020000000000007d870b08000000007c491ecb0000000020e767c5b2706f4d810d28664f9b5b0731d085156a3afcb72ddf67373cdf99057f012067d6d26500b1403a1a464cbd64d0aa61e02eace13f2c267f970f70bd3ed4fd38000000000000000000000000000000000000000000000000000000000000000038aed544960100000000020000e002000000000000")
 	require.NoError(t, err)
-	dstG1 := []byte("BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_NUL_")
-	xmd, err := hash.ExpandMsgXmd(chkBytes, dstG1, 128)
-	if err != nil {
-		return nil
-	}
-	xmd0 := xmd[:64]
-	xmd1 := xmd[64:]
-	var xmds0, xmds1 [3]frontend.Variable
-	xmds0[0] = new(big.Int).SetBytes(xmd0[:2])
-	xmds0[1] = new(big.Int).SetBytes(xmd0[2:33])
-	xmds0[2] = new(big.Int).SetBytes(xmd0[33:])
-	xmds1[0] = new(big.Int).SetBytes(xmd1[:2])
-	xmds1[1] = new(big.Int).SetBytes(xmd1[2:33])
-	xmds1[2] = new(big.Int).SetBytes(xmd1[33:])
+	summary, err := sui.ParseSigningMessage(chkBytes)
+	require.NoError(t, err)
 
-	cr, err := hex.DecodeString("1CF2542241BF7DF9DD50FC28DB1EB8104D7C293D6F53378D17D9688327C7AFBB")
+	// Signers map as serialized by the validators, 69 of the 113 members signed
+	signersMap, err := hex.DecodeString("3a30000001000000000044001000000001000200030004000500070008000b000d000e00110014001500160019001b001c001d001f002100230024002600280029002b002c002d002e002f003100320033003400380039003b003c003d003e003f004000410042004300450046004b004c004d004e00500052005300540058005a005c005d005f0062006400660067006a006b006c006d006e00")
+	require.NoError(t, err)
+	sigBytes, err := hex.DecodeString("9455fd6e9ccdc6157cabf28b7a8e2e161d17a2b167ecaf055b8677f1c43365418edb71fc11b1160405cac49b8c8b1d08")
 	require.NoError(t, err)
 
-	return &circuits.SigVerifyCircuit{
-		CommitteePubKeys:           pubkeys,
-		CommitteeStakeUnits:        committeeStakeUnits,
-		SignerMap:                  signerMap,
-		AggSig:                     sig,
-		CheckpointSummaryExpanded0: xmds0,
-		CheckpointSummaryExpanded1: xmds1,
-		CommitteeRoot:              new(big.Int).SetBytes(cr),
+	c := &sui.CertifiedCheckpointSummary{
+		Summary: *summary,
+		AuthSignature: sui.AuthorityQuorumSignInfo{
+			Epoch:      summary.Epoch,
+			SignersMap: signersMap,
+		},
 	}
+	copy(c.AuthSignature.Signature[:], sigBytes)
+	return c
 }
 
 var pubkeysHex = []string{
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/solidity"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+	"github.com/consensys/gnark/test/unsafekzg"
+	"github.com/patrickmao1/zuika/utils"
+	"github.com/stretchr/testify/require"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestSigVerifyPlonk(t *testing.T) {
+	c := buildTestCircuit(t)
+	a := buildTestCircuit(t)
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(c, a, test.WithBackends(backend.PLONK), test.WithCurves(ecc.BN254))
+}
+
+func TestCompileAndSetupPlonk(t *testing.T) {
+	c := buildTestCircuit(t)
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, c)
+	require.NoError(t, err)
+	t.Log("constraints", ccs.GetNbConstraints())
+	saveArtifact(t, "../build/plonk_ccs", ccs)
+
+	// test only SRS, use utils.KZGSRSForCircuit with a ceremony SRS otherwise
+	srs, srsLagrange, err := unsafekzg.NewSRS(ccs)
+	require.NoError(t, err)
+	pk, vk, err := plonk.Setup(ccs, srs, srsLagrange)
+	require.NoError(t, err)
+	saveArtifact(t, "../build/plonk_pk", pk)
+	saveArtifact(t, "../build/plonk_vk", vk)
+
+	f, err := os.Create("../build/committee_sig_plonk_contract.sol")
+	require.NoError(t, err)
+	defer f.Close()
+	require.NoError(t, vk.ExportSolidity(f))
+}
+
+func TestProvePlonk(t *testing.T) {
+	a := buildTestCircuit(t)
+
+	ccs := plonk.NewCS(ecc.BN254)
+	pk := plonk.NewProvingKey(ecc.BN254)
+	vk := plonk.NewVerifyingKey(ecc.BN254)
+	loadArtifact(t, "../build/plonk_ccs", ccs)
+	loadArtifact(t, "../build/plonk_pk", pk)
+	loadArtifact(t, "../build/plonk_vk", vk)
+
+	w, err := frontend.NewWitness(a, ecc.BN254.ScalarField())
+	require.NoError(t, err)
+	wpub, err := w.Public()
+	require.NoError(t, err)
+
+	proof, err := plonk.Prove(ccs, pk, w, solidity.WithProverTargetSolidityVerifier(backend.PLONK))
+	require.NoError(t, err)
+	err = plonk.Verify(proof, vk, wpub, solidity.WithVerifierTargetSolidityVerifier(backend.PLONK))
+	require.NoError(t, err)
+
+	t.Logf("marshal solidity %x", utils.ExportPlonkProofForSolidity(proof))
+}
+
+// saveArtifact writes v to path, replacing any previous content
+func saveArtifact(t *testing.T, path string, v io.WriterTo) {
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = v.WriteTo(f)
+	require.NoError(t, err)
+}
+
+func loadArtifact(t *testing.T, path string, v io.ReaderFrom) {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = v.ReadFrom(f)
+	require.NoError(t, err)
+}
@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark/backend/groth16"
+	"golang.org/x/crypto/sha3"
+	"math/big"
+)
+
+// UpdateCheckpointSelector is the selector of ZKLightClient.updateCheckpoint(bytes,bytes)
+var UpdateCheckpointSelector = selector("updateCheckpoint(bytes,bytes)")
+
+// CurrentCommitteeRootSelector is the selector of ZKLightClient.currentCommitteeRoot(), whose calldata is the selector
+// alone
+var CurrentCommitteeRootSelector = selector("currentCommitteeRoot()")
+
+func selector(signature string) [4]byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(signature))
+	var sel [4]byte
+	copy(sel[:], h.Sum(nil))
+	return sel
+}
+
+// ProofSize is the size of a proof packed by PackProofForSolidity
+const ProofSize = 12 * 32
+
+// PackProofForSolidity packs the output of ExportProofForSolidity as the uint256[8], uint256[2], uint256[2] head of
+// the verifier's verifyProof arguments. ZKLightClient forwards these bytes to the verifier as is.
+func PackProofForSolidity(p [8]*big.Int, commitments [2]*big.Int, commitmentPoK [2]*big.Int) []byte {
+	b := make([]byte, 0, ProofSize)
+	for _, v := range p {
+		b = append(b, word(v)...)
+	}
+	for _, v := range commitments {
+		b = append(b, word(v)...)
+	}
+	for _, v := range commitmentPoK {
+		b = append(b, word(v)...)
+	}
+	return b
+}
+
+// UnpackSolidityProof is the inverse of PackProofForSolidity
+func UnpackSolidityProof(b []byte) (p [8]*big.Int, commitments [2]*big.Int, commitmentPoK [2]*big.Int, err error) {
+	if len(b) != ProofSize {
+		err = fmt.Errorf("invalid proof length %d", len(b))
+		return
+	}
+	next := func() *big.Int {
+		v := new(big.Int).SetBytes(b[:32])
+		b = b[32:]
+		return v
+	}
+	for i := range p {
+		p[i] = next()
+	}
+	for i := range commitments {
+		commitments[i] = next()
+	}
+	for i := range commitmentPoK {
+		commitmentPoK[i] = next()
+	}
+	return
+}
+
+// UpdateCheckpointCalldata returns the calldata of ZKLightClient.updateCheckpoint(checkpointIntent, zkProof) where
+// zkProof is packed by PackProofForSolidity
+func UpdateCheckpointCalldata(checkpointIntent []byte, zkProof []byte) []byte {
+	data := append([]byte{}, UpdateCheckpointSelector[:]...)
+	data = append(data, uintWord(64)...)
+	data = append(data, uintWord(uint64(64+32+padded(len(checkpointIntent))))...)
+	data = appendBytes(data, checkpointIntent)
+	return appendBytes(data, zkProof)
+}
+
+// ExportUpdateCheckpointCalldata returns the calldata submitting proof of the checkpoint signed with checkpointIntent
+func ExportUpdateCheckpointCalldata(checkpointIntent []byte, proof groth16.Proof) []byte {
+	return UpdateCheckpointCalldata(checkpointIntent, PackProofForSolidity(ExportProofForSolidity(proof)))
+}
+
+// DecodeUpdateCheckpointCalldata is the inverse of UpdateCheckpointCalldata. It only accepts the canonical encoding.
+func DecodeUpdateCheckpointCalldata(data []byte) (checkpointIntent []byte, zkProof []byte, err error) {
+	if len(data) < 4 || [4]byte(data[:4]) != UpdateCheckpointSelector {
+		return nil, nil, errors.New("not an updateCheckpoint call")
+	}
+	args := data[4:]
+	checkpointIntent, end, err := readBytesArg(args, 0, 64)
+	if err != nil {
+		return nil, nil, fmt.Errorf("checkpointIntent: %w", err)
+	}
+	zkProof, end, err = readBytesArg(args, 1, end)
+	if err != nil {
+		return nil, nil, fmt.Errorf("zkProof: %w", err)
+	}
+	if end != uint64(len(args)) {
+		return nil, nil, fmt.Errorf("%d trailing bytes", uint64(len(args))-end)
+	}
+	return checkpointIntent, zkProof, nil
+}
+
+// readBytesArg reads the i-th argument of type bytes, which must start at offset. It returns the offset the argument
+// ends at.
+func readBytesArg(args []byte, i int, offset uint64) (b []byte, end uint64, err error) {
+	head, err := readUint(args, uint64(32*i))
+	if err != nil {
+		return nil, 0, err
+	}
+	if head != offset {
+		return nil, 0, fmt.Errorf("offset %d, expected %d", head, offset)
+	}
+	n, err := readUint(args, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	start := offset + 32
+	end = start + uint64(padded(int(n)))
+	if n > uint64(len(args)) || end > uint64(len(args)) {
+		return nil, 0, fmt.Errorf("length %d out of bounds", n)
+	}
+	for _, v := range args[start+n : end] {
+		if v != 0 {
+			return nil, 0, errors.New("non-zero padding")
+		}
+	}
+	return args[start : start+n], end, nil
+}
+
+func readUint(args []byte, offset uint64) (uint64, error) {
+	if offset+32 > uint64(len(args)) {
+		return 0, fmt.Errorf("word at %d out of bounds", offset)
+	}
+	w := args[offset : offset+32]
+	for _, v := range w[:24] {
+		if v != 0 {
+			return 0, fmt.Errorf("word at %d overflows", offset)
+		}
+	}
+	return binary.BigEndian.Uint64(w[24:]), nil
+}
+
+func appendBytes(data, b []byte) []byte {
+	data = append(data, uintWord(uint64(len(b)))...)
+	data = append(data, b...)
+	return append(data, make([]byte, padded(len(b))-len(b))...)
+}
+
+func padded(n int) int {
+	return (n + 31) / 32 * 32
+}
+
+func word(v *big.Int) []byte {
+	return v.FillBytes(make([]byte, 32))
+}
+
+func uintWord(v uint64) []byte {
+	w := make([]byte, 32)
+	binary.BigEndian.PutUint64(w[24:], v)
+	return w
+}
@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"encoding/hex"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	groth16bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// testProof has arbitrary points in place of a proof, the encoding does not check them
+func testProof() *groth16bn254.Proof {
+	_, _, g1, g2 := bn254.Generators()
+	proof := &groth16bn254.Proof{Commitments: make([]bn254.G1Affine, 1)}
+	proof.Ar.ScalarMultiplication(&g1, big.NewInt(2))
+	proof.Bs.ScalarMultiplication(&g2, big.NewInt(3))
+	proof.Krs.ScalarMultiplication(&g1, big.NewInt(4))
+	proof.Commitments[0].ScalarMultiplication(&g1, big.NewInt(5))
+	proof.CommitmentPok.ScalarMultiplication(&g1, big.NewInt(6))
+	return proof
+}
+
+func TestUpdateCheckpointCalldata(t *testing.T) {
+	require.Equal(t, "c25a8b99", hex.EncodeToString(UpdateCheckpointSelector[:]))
+
+	zkProof := make([]byte, ProofSize)
+	for i := range zkProof {
+		zkProof[i] = byte(i)
+	}
+	data := UpdateCheckpointCalldata([]byte{0xaa, 0xbb, 0xcc}, zkProof)
+	expected := "c25a8b99" +
+		// offsets
+		strings.Repeat("0", 62) + "40" +
+		strings.Repeat("0", 62) + "80" +
+		// checkpointIntent
+		strings.Repeat("0", 63) + "3" +
+		"aabbcc" + strings.Repeat("0", 58) +
+		// zkProof
+		strings.Repeat("0", 61) + "180" +
+		hex.EncodeToString(zkProof)
+	require.Equal(t, expected, hex.EncodeToString(data))
+}
+
+func TestUpdateCheckpointCalldataRoundTrip(t *testing.T) {
+	proof := testProof()
+	p, commitments, commitmentPoK := ExportProofForSolidity(proof)
+	for _, n := range []int{0, 1, 31, 32, 33, 152} {
+		intent := make([]byte, n)
+		for i := range intent {
+			intent[i] = byte(i + 1)
+		}
+		data := ExportUpdateCheckpointCalldata(intent, proof)
+		require.Zero(t, (len(data)-4)%32)
+
+		decodedIntent, zkProof, err := DecodeUpdateCheckpointCalldata(data)
+		require.NoError(t, err)
+		require.Equal(t, intent, decodedIntent)
+		decodedP, decodedCommitments, decodedCommitmentPoK, err := UnpackSolidityProof(zkProof)
+		require.NoError(t, err)
+		require.Equal(t, p, decodedP)
+		require.Equal(t, commitments, decodedCommitments)
+		require.Equal(t, commitmentPoK, decodedCommitmentPoK)
+	}
+}
+
+func TestDecodeUpdateCheckpointCalldataInvalid(t *testing.T) {
+	data := UpdateCheckpointCalldata([]byte{1, 2, 3}, make([]byte, ProofSize))
+	corrupt := func(i int, v byte) []byte {
+		b := append([]byte{}, data...)
+		b[i] = v
+		return b
+	}
+	for name, b := range map[string][]byte{
+		"selector":  corrupt(0, 0),
+		"offset":    corrupt(4+31, 0x60),
+		"overflow":  corrupt(4+64, 1),
+		"length":    corrupt(4+64+31, 0xff),
+		"padding":   corrupt(4+96+31, 1),
+		"truncated": data[:len(data)-1],
+		"trailing":  append(append([]byte{}, data...), make([]byte, 32)...),
+	} {
+		_, _, err := DecodeUpdateCheckpointCalldata(b)
+		require.Error(t, err, name)
+	}
+	_, _, _, err := UnpackSolidityProof(make([]byte, ProofSize-1))
+	require.Error(t, err)
+}
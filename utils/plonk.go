@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	gckzg "github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend/plonk"
+	plonkbn254 "github.com/consensys/gnark/backend/plonk/bn254"
+	"github.com/consensys/gnark/constraint"
+)
+
+// ExportPlonkProofForSolidity returns the proof bytes expected by the Solidity verifier exported from a PLONK
+// verifying key
+func ExportPlonkProofForSolidity(proof plonk.Proof) []byte {
+	return proof.(*plonkbn254.Proof).MarshalSolidity()
+}
+
+// KZGSRSForCircuit sizes a universal BN254 KZG SRS (e.g. from a powers of tau ceremony) for ccs. It returns the
+// canonical and Lagrange form SRS expected by plonk.Setup.
+func KZGSRSForCircuit(ccs constraint.ConstraintSystem, srs *kzg.SRS) (canonical, lagrange gckzg.SRS, err error) {
+	sizeCanonical, sizeLagrange := plonk.SRSSize(ccs)
+	if len(srs.Pk.G1) < sizeCanonical {
+		return nil, nil, fmt.Errorf("SRS too small: %d points, circuit needs %d", len(srs.Pk.G1), sizeCanonical)
+	}
+	lagrangeG1, err := kzg.ToLagrangeG1(srs.Pk.G1[:sizeLagrange])
+	if err != nil {
+		return nil, nil, err
+	}
+	canonical = &kzg.SRS{Pk: kzg.ProvingKey{G1: srs.Pk.G1[:sizeCanonical]}, Vk: srs.Vk}
+	lagrange = &kzg.SRS{Pk: kzg.ProvingKey{G1: lagrangeG1}, Vk: srs.Vk}
+	return canonical, lagrange, nil
+}
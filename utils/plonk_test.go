@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+type cubeCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *cubeCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X, c.X), c.Y)
+	return nil
+}
+
+func TestKZGSRSForCircuit(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &cubeCircuit{})
+	require.NoError(t, err)
+	sizeCanonical, sizeLagrange := plonk.SRSSize(ccs)
+
+	// a universal SRS larger than the circuit needs, as from a ceremony
+	srs, err := kzg.NewSRS(uint64(2*sizeCanonical), big.NewInt(42))
+	require.NoError(t, err)
+	canonical, lagrange, err := KZGSRSForCircuit(ccs, srs)
+	require.NoError(t, err)
+	require.Len(t, canonical.(*kzg.SRS).Pk.G1, sizeCanonical)
+	require.Len(t, lagrange.(*kzg.SRS).Pk.G1, sizeLagrange)
+
+	pk, vk, err := plonk.Setup(ccs, canonical, lagrange)
+	require.NoError(t, err)
+	w, err := frontend.NewWitness(&cubeCircuit{X: 3, Y: 27}, ecc.BN254.ScalarField())
+	require.NoError(t, err)
+	proof, err := plonk.Prove(ccs, pk, w)
+	require.NoError(t, err)
+	pub, err := w.Public()
+	require.NoError(t, err)
+	require.NoError(t, plonk.Verify(proof, vk, pub))
+
+	small, err := kzg.NewSRS(uint64(sizeCanonical-1), big.NewInt(42))
+	require.NoError(t, err)
+	_, _, err = KZGSRSForCircuit(ccs, small)
+	require.Error(t, err)
+}